@@ -2,10 +2,15 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/carylee/adf2md/pkg/adf2md"
 	"github.com/spf13/pflag"
@@ -21,11 +26,69 @@ func main() {
 		showVersion bool
 		inputFile   string
 		outputFile  string
+		format      string
+		inputFormat string
+		pageID      string
+		standalone  bool
+		title       string
+		width       int
+		bullets     string
+		numbering   string
+		linkStyle   string
+		tableMode   string
+		cardStyle   string
+		layoutMode  string
+		separator   string
+		userMap     string
+		mentionTmpl string
+		mediaBase   string
+		mediaSource string
+		mediaTmpl   string
+		assetsDir   string
+		panelStyle  string
+		statusStyle string
+		dateStyle   string
+		dateFormat  string
+		timeZone    string
+		noEscape    bool
+		flatten     bool
+		reverse     bool
+		strict      bool
+		warnings    bool
 	)
 
 	pflag.BoolVarP(&showVersion, "version", "v", false, "Print version information")
 	pflag.StringVarP(&inputFile, "input", "i", "", "Input file containing ADF JSON (default: stdin)")
 	pflag.StringVarP(&outputFile, "output", "o", "", "Output file for Markdown (default: stdout)")
+	pflag.StringVar(&inputFormat, "input-format", "adf", "Input format: adf (JSON) or storage (Confluence storage format XHTML)")
+	pflag.StringVar(&pageID, "page-id", "", "ID of the Confluence page storage format input comes from, used to locate its attachments")
+	pflag.StringVarP(&format, "format", "f", "markdown", "Output format: markdown, html, wiki (Jira wiki markup), slack (mrkdwn) or text")
+	pflag.BoolVar(&standalone, "standalone", false, "Wrap HTML output in a complete page with a minimal stylesheet")
+	pflag.StringVar(&title, "title", "", "Title of a standalone HTML page")
+	pflag.IntVar(&width, "width", 0, "Wrap text output at this many characters (default: no wrapping)")
+	pflag.StringVar(&bullets, "bullets", "", "Comma-separated bullet glyphs for each level of nesting in text output (default: •,◦,▪)")
+	pflag.StringVar(&numbering, "numbering", "decimal", "Numbered list items in text output: decimal, outline (1.2.) or none")
+	pflag.StringVar(&linkStyle, "link-style", "inline", "Links in text output: inline (text (url)) or text")
+	pflag.StringVar(&tableMode, "table-mode", "auto", "Table rendering: auto, gfm or html")
+	pflag.StringVar(&cardStyle, "card-style", "link", "Smart link rendering: link, autolink or card")
+	pflag.StringVar(&layoutMode, "layout-mode", "sequential", "Multi-column layout rendering: sequential, html or table")
+	pflag.StringVar(&separator, "layout-separator", "", "Line written between layout columns in sequential mode, e.g. ---")
+	pflag.StringVar(&userMap, "user-map", "", "CSV or JSON file mapping account IDs to user names, emails and profile URLs")
+	pflag.StringVar(&mentionTmpl, "mention-template", "", "Template for mentions using {id}, {name}, {email} and {url}, e.g. '[@{name}]({url})'")
+	pflag.StringVar(&mediaBase, "media-base-url", "", "Base URL of the site attachments are on, e.g. https://example.atlassian.net")
+	pflag.StringVar(&mediaSource, "media-source", "confluence", "Product attachments come from when --media-base-url is set: confluence or jira")
+	pflag.StringVar(&mediaTmpl, "media-url-template", "", "Template for attachment URLs using {id}, {collection}, {type}, {alt}, {width} and {height}")
+	pflag.StringVar(&assetsDir, "assets-dir", "", "Download attachments and images into this directory and link to the local copies")
+	pflag.StringVar(&panelStyle, "panel-style", "legacy", "Panel rendering: legacy, github (alerts), obsidian (callouts) or admonition (:::note)")
+	pflag.StringVar(&statusStyle, "status-style", "brackets", "Status lozenge rendering: brackets, code, emoji, html or badge")
+	pflag.StringVar(&dateStyle, "date-style", "plain", "Date rendering: plain, iso or html (a <time> element)")
+	pflag.StringVar(&dateFormat, "date-format", adf2md.DefaultDateLayout, "Go time layout for dates, e.g. 'Jan 2, 2006'")
+	pflag.StringVar(&timeZone, "timezone", "UTC", "Time zone dates are shown in, e.g. Europe/London or Local")
+	pflag.BoolVar(&noEscape, "no-escape", false, "Write text verbatim without escaping Markdown syntax")
+	pflag.BoolVar(&flatten, "flatten-expands", false, "Render expands as a bold title and their content instead of HTML <details>")
+	pflag.BoolVarP(&reverse, "reverse", "r", false, "Convert Markdown input to ADF JSON instead")
+	pflag.BoolVar(&strict, "strict", false, "Fail if the document contains nodes or marks that can't be converted")
+	pflag.BoolVar(&warnings, "warnings", false, "Print warnings about content that couldn't be converted faithfully to stderr")
 	
 	// Add help flag explicitly
 	help := pflag.BoolP("help", "h", false, "Show help information")
@@ -36,7 +99,9 @@ func main() {
 	// Show help if requested
 	if *help {
 		fmt.Printf("adf2md - Convert Atlassian Document Format (ADF) JSON to Markdown\n\n")
-		fmt.Printf("Usage: adf2md [options] [json-string]\n\n")
+		fmt.Printf("Usage: adf2md [options] [json-string]\n")
+		fmt.Printf("       adf2md --reverse [options] [markdown-string]\n")
+		fmt.Printf("       adf2md validate [options] [json-string]\n\n")
 		fmt.Printf("Options:\n")
 		pflag.PrintDefaults()
 		os.Exit(0)
@@ -48,9 +113,149 @@ func main() {
 		os.Exit(0)
 	}
 
+	// The validate subcommand checks the input against the ADF schema
+	// instead of converting it
+	args := pflag.Args()
+	validate := len(args) > 0 && args[0] == "validate"
+	if validate {
+		args = args[1:]
+	}
+
+	tableModes := map[string]adf2md.TableMode{
+		"auto": adf2md.TableModeAuto,
+		"gfm":  adf2md.TableModeGFM,
+		"html": adf2md.TableModeHTML,
+	}
+	mode, ok := tableModes[tableMode]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid table mode: %s\n", tableMode)
+		os.Exit(1)
+	}
+
+	inputFormats := map[string]func(string) (*adf2md.Node, error){
+		"adf": adf2md.ParseADF,
+		"storage": func(storage string) (*adf2md.Node, error) {
+			return adf2md.ParseStorageWithOptions(storage, adf2md.StorageOptions{PageID: pageID})
+		},
+	}
+	inputNames := map[string]string{"adf": "ADF", "storage": "storage format"}
+	parse, ok := inputFormats[inputFormat]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid input format: %s\n", inputFormat)
+		os.Exit(1)
+	}
+	if inputFormat != "adf" && (reverse || validate) {
+		fmt.Fprintf(os.Stderr, "--input-format only applies when converting to an output format\n")
+		os.Exit(1)
+	}
+
+	formats := map[string]bool{"markdown": true, "html": true, "wiki": true, "slack": true, "text": true}
+	if !formats[format] {
+		fmt.Fprintf(os.Stderr, "Invalid output format: %s\n", format)
+		os.Exit(1)
+	}
+	if warnings && (format == "html" || format == "text") {
+		fmt.Fprintf(os.Stderr, "--warnings is only supported for Markdown, wiki and Slack output\n")
+		os.Exit(1)
+	}
+
+	numberings := map[string]adf2md.Numbering{
+		"decimal": adf2md.NumberingDecimal,
+		"outline": adf2md.NumberingOutline,
+		"none":    adf2md.NumberingNone,
+	}
+	number, ok := numberings[numbering]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid numbering: %s\n", numbering)
+		os.Exit(1)
+	}
+
+	linkStyles := map[string]adf2md.LinkStyle{
+		"inline": adf2md.LinkStyleInline,
+		"text":   adf2md.LinkStyleTextOnly,
+	}
+	links, ok := linkStyles[linkStyle]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid link style: %s\n", linkStyle)
+		os.Exit(1)
+	}
+
+	cardStyles := map[string]adf2md.CardStyle{
+		"link":     adf2md.CardStyleLink,
+		"autolink": adf2md.CardStyleAutolink,
+		"card":     adf2md.CardStyleCard,
+	}
+	cards, ok := cardStyles[cardStyle]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid card style: %s\n", cardStyle)
+		os.Exit(1)
+	}
+
+	layoutModes := map[string]adf2md.LayoutMode{
+		"sequential": adf2md.LayoutModeSequential,
+		"html":       adf2md.LayoutModeHTML,
+		"table":      adf2md.LayoutModeTable,
+	}
+	layout, ok := layoutModes[layoutMode]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid layout mode: %s\n", layoutMode)
+		os.Exit(1)
+	}
+
+	mediaSources := map[string]func(string) adf2md.MediaResolver{
+		"confluence": adf2md.ConfluenceMediaResolver,
+		"jira":       adf2md.JiraMediaResolver,
+	}
+	mediaResolver, ok := mediaSources[mediaSource]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid media source: %s\n", mediaSource)
+		os.Exit(1)
+	}
+
+	panelStyles := map[string]adf2md.PanelStyle{
+		"legacy":     adf2md.PanelStyleLegacy,
+		"github":     adf2md.PanelStyleGitHub,
+		"obsidian":   adf2md.PanelStyleObsidian,
+		"admonition": adf2md.PanelStyleAdmonition,
+	}
+	panels, ok := panelStyles[panelStyle]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid panel style: %s\n", panelStyle)
+		os.Exit(1)
+	}
+
+	statusStyles := map[string]adf2md.StatusStyle{
+		"brackets": adf2md.StatusStyleBrackets,
+		"code":     adf2md.StatusStyleCode,
+		"emoji":    adf2md.StatusStyleEmoji,
+		"html":     adf2md.StatusStyleHTML,
+		"badge":    adf2md.StatusStyleBadge,
+	}
+	statuses, ok := statusStyles[statusStyle]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid status style: %s\n", statusStyle)
+		os.Exit(1)
+	}
+
+	dateStyles := map[string]adf2md.DateStyle{
+		"plain": adf2md.DateStylePlain,
+		"iso":   adf2md.DateStyleISO,
+		"html":  adf2md.DateStyleHTML,
+	}
+	dates, ok := dateStyles[dateStyle]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid date style: %s\n", dateStyle)
+		os.Exit(1)
+	}
+
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid time zone: %s\n", timeZone)
+		os.Exit(1)
+	}
+
 	// Get input content
 	var input []byte
-	var err error
 	
 	if inputFile != "" {
 		// Read from file
@@ -61,8 +266,8 @@ func main() {
 		}
 	} else {
 		// Check if there's extra argument as content
-		if pflag.NArg() > 0 {
-			input = []byte(pflag.Arg(0))
+		if len(args) > 0 {
+			input = []byte(args[0])
 		} else {
 			// Read from stdin
 			input, err = readStdin()
@@ -73,33 +278,229 @@ func main() {
 		}
 	}
 
-	// Parse ADF JSON
-	node, err := adf2md.ParseADF(string(input))
+	if validate {
+		node, err := adf2md.ParseADF(string(input))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing ADF: %v\n", err)
+			os.Exit(1)
+		}
+
+		errs := adf2md.Validate(node)
+		for _, err := range errs {
+			fmt.Println(err)
+		}
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Convert Markdown to ADF JSON
+	if reverse {
+		node, err := adf2md.ParseMarkdown(string(input))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing Markdown: %v\n", err)
+			os.Exit(1)
+		}
+
+		var adf strings.Builder
+		encoder := json.NewEncoder(&adf)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(node); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding ADF: %v\n", err)
+			os.Exit(1)
+		}
+
+		writeOutput(outputFile, adf.String())
+		return
+	}
+
+	// Parse ADF JSON, or storage format into the same tree
+	node, err := parse(string(input))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing ADF: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error parsing %s: %v\n", inputNames[inputFormat], err)
 		os.Exit(1)
 	}
 
+	var resolver adf2md.MentionResolver
+	if userMap != "" {
+		users, err := loadUserMap(userMap)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading user map: %v\n", err)
+			os.Exit(1)
+		}
+		resolver = users
+	}
+
+	// Attachments keep their default links unless told where they live
+	var media adf2md.MediaResolver
+	if mediaTmpl != "" {
+		media = adf2md.TemplateMediaResolver(mediaTmpl)
+	} else if mediaBase != "" {
+		media = mediaResolver(mediaBase)
+	}
+
+	// Downloaded files are linked relative to the Markdown file
+	var downloader *adf2md.AssetDownloader
+	if assetsDir != "" {
+		prefix := assetsDir
+		if outputFile != "" {
+			if rel, err := filepath.Rel(filepath.Dir(outputFile), assetsDir); err == nil {
+				prefix = rel
+			}
+		}
+		downloader = &adf2md.AssetDownloader{
+			Dir:        assetsDir,
+			LinkPrefix: filepath.ToSlash(prefix),
+			Resolver:   media,
+		}
+		media = downloader
+	}
+
 	// Convert to Markdown with default indent of 2
-	renderer := adf2md.NewRenderer().WithOptions(adf2md.RenderOptions{
-		ListIndent: 2,
-	})
-	
-	markdown, err := renderer.RenderToMarkdown(node)
+	options := adf2md.RenderOptions{
+		ListIndent:      2,
+		TableMode:       mode,
+		CardStyle:       cards,
+		FlattenExpands:  flatten,
+		LayoutMode:      layout,
+		LayoutSeparator: separator,
+		MentionResolver: resolver,
+		MentionTemplate: mentionTmpl,
+		MediaResolver:   media,
+		PanelStyle:      panels,
+		StatusStyle:     statuses,
+		DateStyle:       dates,
+		DateLayout:      dateFormat,
+		DateLocation:    location,
+		DisableEscaping: noEscape,
+		Strict:          strict,
+	}
+	// Markdown, wiki markup and Slack mrkdwn share a renderer, which can
+	// report warnings
+	markup := adf2md.NewRenderer()
+	switch format {
+	case "wiki":
+		markup = adf2md.NewWikiRenderer()
+	case "slack":
+		markup = adf2md.NewSlackRenderer()
+	}
+	markup = markup.WithOptions(options)
+
+	// Other formats share the options that apply to them
+	var renderer interface {
+		Render(w io.Writer, node *adf2md.Node) error
+	}
+	switch format {
+	case "html":
+		renderer = adf2md.NewHTMLRenderer().WithOptions(adf2md.HTMLOptions{
+			RenderOptions: options,
+			Standalone:    standalone,
+			Title:         title,
+		})
+	case "text":
+		var glyphs []string
+		if bullets != "" {
+			glyphs = strings.Split(bullets, ",")
+		}
+		renderer = adf2md.NewPlainTextRenderer().WithOptions(adf2md.PlainTextOptions{
+			RenderOptions: options,
+			Width:         width,
+			Bullets:       glyphs,
+			Numbering:     number,
+			LinkStyle:     links,
+		})
+	default:
+		renderer = markup
+	}
+
+	// Warnings are only known once the whole document is rendered
+	if warnings {
+		result, err := markup.RenderToResult(node)
+		reportDownloadErrors(downloader)
+		if err != nil {
+			exitRenderError(err)
+		}
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+		writeOutput(outputFile, result.Markdown)
+		return
+	}
+
+	// Stream output to stdout. A file is only written once rendering has
+	// succeeded, so a failure leaves any existing file as it was.
+	if outputFile == "" {
+		err := renderer.Render(os.Stdout, node)
+		reportDownloadErrors(downloader)
+		if err != nil {
+			exitRenderError(err)
+		}
+		return
+	}
+
+	var output bytes.Buffer
+	err = renderer.Render(&output, node)
+	reportDownloadErrors(downloader)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error rendering Markdown: %v\n", err)
+		exitRenderError(err)
+	}
+	writeOutput(outputFile, output.String())
+}
+
+// reportDownloadErrors prints a warning for each attachment that couldn't
+// be downloaded. Rendering is what fetches them, so this runs once it's
+// over, whether or not it succeeded.
+func reportDownloadErrors(downloader *adf2md.AssetDownloader) {
+	if downloader == nil {
+		return
+	}
+	for _, err := range downloader.Errors() {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// exitRenderError reports an error from rendering Markdown and exits
+func exitRenderError(err error) {
+	var unsupported *adf2md.UnsupportedError
+	if errors.As(err, &unsupported) {
+		fmt.Fprintf(os.Stderr, "Unsupported ADF content:\n")
+		for _, u := range unsupported.Unsupported {
+			fmt.Fprintf(os.Stderr, "  %s\n", u)
+		}
 		os.Exit(1)
 	}
 
-	// Write output
+	fmt.Fprintf(os.Stderr, "Error rendering Markdown: %v\n", err)
+	os.Exit(1)
+}
+
+// loadUserMap reads a user map from a CSV or JSON file, telling them apart
+// by the file extension
+func loadUserMap(path string) (adf2md.UserMap, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return adf2md.ReadUserMapJSON(file)
+	}
+	return adf2md.ReadUserMapCSV(file)
+}
+
+// writeOutput writes the converted document to a file, or stdout if no file is given
+func writeOutput(outputFile string, output string) {
 	if outputFile != "" {
-		err = os.WriteFile(outputFile, []byte(markdown), 0644)
+		err := os.WriteFile(outputFile, []byte(output), 0644)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error writing output file: %v\n", err)
 			os.Exit(1)
 		}
 	} else {
-		fmt.Print(markdown)
+		fmt.Print(output)
 	}
 }
 
@@ -2,4 +2,4 @@ module github.com/carylee/adf2md
 
 go 1.24.3
 
-require github.com/spf13/pflag v1.0.6 // indirect
+require github.com/spf13/pflag v1.0.6
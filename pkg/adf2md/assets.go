@@ -0,0 +1,167 @@
+package adf2md
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+)
+
+// AssetDownloader is a MediaResolver that downloads media into a local
+// directory and links to the saved copies, so the Markdown doesn't depend on
+// the site the document came from. Files are named after a hash of their
+// content, so each media ID is only downloaded once and identical files are
+// only saved once.
+//
+// Attachments that can't be downloaded are rendered as unresolved
+// placeholders, external media that can't be downloaded keep linking to
+// their URL, and the failures are reported by Errors.
+type AssetDownloader struct {
+	// The directory the files are saved in, which is created if needed
+	Dir string
+
+	// The path links start with, e.g. "assets" when the Markdown is saved
+	// next to the directory. Defaults to Dir.
+	LinkPrefix string
+
+	// The client used to download files, which can add authentication
+	// headers in its Transport. Defaults to http.DefaultClient.
+	Client *http.Client
+
+	// Works out where to download attachments from. Without it only
+	// external media can be downloaded, as attachments have no absolute URL.
+	Resolver MediaResolver
+
+	mu    sync.Mutex
+	links map[string]string
+	errs  []error
+}
+
+// ResolveMedia downloads the media, unless it has already been downloaded,
+// and returns the path of the saved file
+func (d *AssetDownloader) ResolveMedia(media Media) (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := media.ID
+	if key == "" {
+		key = media.URL
+	}
+	if key == "" {
+		return "", false
+	}
+	if link, ok := d.links[key]; ok {
+		return link, link != ""
+	}
+
+	link, err := d.download(media)
+	if err != nil {
+		d.errs = append(d.errs, fmt.Errorf("error downloading %s: %w", key, err))
+	}
+	if d.links == nil {
+		d.links = make(map[string]string)
+	}
+	// Failures are remembered too, so they are only reported once
+	d.links[key] = link
+	return link, link != ""
+}
+
+// Errors returns the errors from every download that failed
+func (d *AssetDownloader) Errors() []error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]error(nil), d.errs...)
+}
+
+// download fetches a file and saves it under a name derived from its
+// content, returning the link to it
+func (d *AssetDownloader) download(media Media) (string, error) {
+	source, ok := media.URL, media.URL != ""
+	if d.Resolver != nil && media.URL == "" {
+		source, ok = d.Resolver.ResolveMedia(media)
+	}
+	if !ok {
+		return "", errors.New("no URL to download from")
+	}
+	if u, err := url.Parse(source); err != nil || !u.IsAbs() {
+		return "", fmt.Errorf("can't download from %q", source)
+	}
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(source)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned %s", source, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(data)
+	name := hex.EncodeToString(hash[:16]) + assetExtension(media, source, resp.Header.Get("Content-Type"))
+
+	if err := os.MkdirAll(d.Dir, 0o755); err != nil {
+		return "", err
+	}
+	file := filepath.Join(d.Dir, name)
+	if _, err := os.Stat(file); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(file, data, 0o644); err != nil {
+			return "", err
+		}
+	}
+
+	prefix := d.LinkPrefix
+	if prefix == "" {
+		prefix = filepath.ToSlash(d.Dir)
+	}
+	return path.Join(prefix, name), nil
+}
+
+// assetExtension picks the file extension for a download from the file
+// name, the URL or the content type, in that order
+func assetExtension(media Media, source, contentType string) string {
+	if ext := path.Ext(fileName(media)); isFileExtension(ext) {
+		return ext
+	}
+	if u, err := url.Parse(source); err == nil {
+		if ext := path.Ext(u.Path); isFileExtension(ext) {
+			return ext
+		}
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+			return exts[0]
+		}
+	}
+	return ""
+}
+
+// isFileExtension reports whether ext looks like a real file extension
+// rather than part of a name such as "v1.2 final"
+func isFileExtension(ext string) bool {
+	if len(ext) < 2 || len(ext) > 6 {
+		return false
+	}
+	for _, r := range ext[1:] {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package adf2md_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestAssetDownloader(t *testing.T) {
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/secure/attachment/img-1/chart.png", "/logo.png":
+			w.Write([]byte("same image"))
+		case "/secure/attachment/doc-1/spec.pdf":
+			w.Write([]byte("a document"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	input := `{"version":1,"type":"doc","content":[
+		{"type":"mediaSingle","content":[{"type":"media","attrs":{"type":"file","id":"img-1","collection":"","alt":"chart.png"}}]},
+		{"type":"mediaSingle","content":[{"type":"media","attrs":{"type":"file","id":"img-1","collection":"","alt":"chart.png"}}]},
+		{"type":"mediaSingle","content":[{"type":"media","attrs":{"type":"external","url":"` + server.URL + `/logo.png"}}]},
+		{"type":"paragraph","content":[{"type":"mediaInline","attrs":{"type":"file","id":"doc-1","collection":"","alt":"spec.pdf"}}]},
+		{"type":"mediaSingle","content":[{"type":"media","attrs":{"type":"file","id":"gone","collection":"","alt":"gone.png"}}]}
+	]}`
+
+	node, err := adf2md.ParseADF(input)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	dir := t.TempDir()
+	downloader := &adf2md.AssetDownloader{
+		Dir:        filepath.Join(dir, "assets"),
+		LinkPrefix: "assets",
+		Client:     server.Client(),
+		Resolver:   adf2md.JiraMediaResolver(server.URL),
+	}
+
+	renderer := adf2md.NewRenderer().WithOptions(adf2md.RenderOptions{MediaResolver: downloader})
+	result, err := renderer.RenderToMarkdown(node)
+	if err != nil {
+		t.Fatalf("RenderToMarkdown failed: %v", err)
+	}
+
+	// sha256("same image") and sha256("a document"), cut to 16 bytes
+	image := "assets/e0ded698dcebe5513cf283ec4a3e8930.png"
+	document := "assets/0d1da5138c035ffb7a848cfa108a901b.pdf"
+	expected := "![chart.png](" + image + ")\n\n![chart.png](" + image + ")\n\n![image](" + image + ")\n\n[spec.pdf](" + document + ")\n\n[Image: gone.png - Type: file]\n\n"
+	if result != expected {
+		t.Errorf("\nExpected: %q\nGot:      %q", expected, result)
+	}
+
+	if requests["/secure/attachment/img-1/chart.png"] != 1 {
+		t.Errorf("Expected img-1 to be downloaded once, got %d requests", requests["/secure/attachment/img-1/chart.png"])
+	}
+
+	files, err := os.ReadDir(filepath.Join(dir, "assets"))
+	if err != nil {
+		t.Fatalf("Failed to read assets directory: %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("Expected 2 saved files, got %d", len(files))
+	}
+
+	if errs := downloader.Errors(); len(errs) != 1 {
+		t.Errorf("Expected 1 error for the missing attachment, got %v", errs)
+	}
+}
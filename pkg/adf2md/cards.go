@@ -0,0 +1,105 @@
+package adf2md
+
+// CardStyle controls how smart links (inlineCard, blockCard and embedCard
+// nodes) are rendered
+type CardStyle int
+
+const (
+	// CardStyleLink renders a link labelled with the linked page's name,
+	// or a bare autolink when the document doesn't include the name
+	CardStyleLink CardStyle = iota
+	// CardStyleAutolink always renders a bare autolink such as
+	// <https://example.com>
+	CardStyleAutolink
+	// CardStyleCard renders block and embed cards as a quoted card with
+	// the page's name and summary. Inline cards are rendered as links.
+	CardStyleCard
+)
+
+// renderCard renders an inlineCard, blockCard or embedCard node
+func (r *Renderer) renderCard(w *markdownWriter, node *Node) {
+	url, name, summary := cardDetails(node)
+	if url == "" {
+		w.state.warn(WarningMissingAttr, node.Type+" has no url")
+		return
+	}
+
+	inline := node.Type == "inlineCard"
+	if !inline && r.options.CardStyle == CardStyleCard {
+		r.renderLinkCard(w, url, name, summary)
+		return
+	}
+
+	if r.options.CardStyle == CardStyleAutolink {
+		name = ""
+	}
+	w.WriteString(r.cardLink(url, name))
+
+	if !inline {
+		w.ensureNewlines(2)
+	}
+}
+
+// renderLinkCard renders a block-level card as a blockquote holding the
+// link in bold, followed by the summary
+func (r *Renderer) renderLinkCard(w *markdownWriter, url, name, summary string) {
+	if name == "" {
+		name = url
+	}
+
+	w.pushPrefix("> ", "> ")
+	w.WriteString("**" + r.cardLink(url, name) + "**")
+	if summary != "" {
+		w.ensureNewlines(1)
+		if !r.options.DisableEscaping {
+			summary = escapeLineStarts(escapeText(summary, escapeInline), true)
+		}
+		w.WriteString(summary)
+	}
+	w.popPrefix()
+
+	w.ensureNewlines(2)
+}
+
+// cardLink returns a Markdown link to url labelled with name, or an
+// autolink if there is no name
+func (r *Renderer) cardLink(url, name string) string {
+	if r.options.DisableEscaping {
+		if name == "" {
+			return "<" + url + ">"
+		}
+		return "[" + name + "](" + url + ")"
+	}
+
+	// Autolinks can't hold spaces or angle brackets
+	if name == "" && linkDestination(url) == url {
+		return "<" + url + ">"
+	}
+	if name == "" {
+		name = url
+	}
+	return "[" + escapeText(name, escapeLinkLabel) + "](" + linkDestination(url) + ")"
+}
+
+// cardDetails returns the URL of a smart link and the name and summary of
+// the page it links to. These come from the url attribute and from the
+// JSON-LD data that Atlassian stores for resolved links.
+func cardDetails(node *Node) (url, name, summary string) {
+	url, _ = node.Attrs["url"].(string)
+
+	data, _ := node.Attrs["data"].(map[string]any)
+	name, _ = data["name"].(string)
+	summary, _ = data["summary"].(string)
+
+	if url == "" {
+		// JSON-LD allows the url to be a plain string or a Link object
+		switch dataURL := data["url"].(type) {
+		case string:
+			url = dataURL
+		case map[string]any:
+			url, _ = dataURL["href"].(string)
+		}
+	}
+
+	return url, name, summary
+}
@@ -0,0 +1,81 @@
+package adf2md_test
+
+import (
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestRenderCards(t *testing.T) {
+	inline := `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"See "},{"type":"inlineCard","attrs":{"url":"https://example.atlassian.net/browse/PROJ-1"}},{"type":"text","text":" and "},{"type":"inlineCard","attrs":{"data":{"@context":"https://www.w3.org/ns/activitystreams","@type":"Document","name":"Design [draft]","url":"https://example.com/design"}}}]}]}`
+	block := `{"version":1,"type":"doc","content":[{"type":"blockCard","attrs":{"url":"https://example.com/page","data":{"name":"Release plan","summary":"Dates and owners"}}},{"type":"embedCard","attrs":{"url":"https://example.com/a b","layout":"center"}}]}`
+
+	tests := []struct {
+		name     string
+		style    adf2md.CardStyle
+		input    string
+		expected string
+	}{
+		{
+			name:     "Inline cards as links",
+			style:    adf2md.CardStyleLink,
+			input:    inline,
+			expected: "See <https://example.atlassian.net/browse/PROJ-1> and [Design \\[draft\\]](https://example.com/design)\n\n",
+		},
+		{
+			name:     "Inline cards as autolinks",
+			style:    adf2md.CardStyleAutolink,
+			input:    inline,
+			expected: "See <https://example.atlassian.net/browse/PROJ-1> and <https://example.com/design>\n\n",
+		},
+		{
+			name:     "Inline cards stay inline in card style",
+			style:    adf2md.CardStyleCard,
+			input:    inline,
+			expected: "See <https://example.atlassian.net/browse/PROJ-1> and [Design \\[draft\\]](https://example.com/design)\n\n",
+		},
+		{
+			name:     "Block cards as links",
+			style:    adf2md.CardStyleLink,
+			input:    block,
+			expected: "[Release plan](https://example.com/page)\n\n[https://example.com/a b](<https://example.com/a b>)\n\n",
+		},
+		{
+			name:     "Block cards as autolinks",
+			style:    adf2md.CardStyleAutolink,
+			input:    block,
+			expected: "<https://example.com/page>\n\n[https://example.com/a b](<https://example.com/a b>)\n\n",
+		},
+		{
+			name:     "Block cards as cards",
+			style:    adf2md.CardStyleCard,
+			input:    block,
+			expected: "> **[Release plan](https://example.com/page)**\n> Dates and owners\n\n> **[https://example.com/a b](<https://example.com/a b>)**\n\n",
+		},
+		{
+			name:     "Link object in JSON-LD data",
+			style:    adf2md.CardStyleLink,
+			input:    `{"version":1,"type":"doc","content":[{"type":"blockCard","attrs":{"data":{"name":"Board","url":{"type":"Link","href":"https://example.com/board"}}}}]}`,
+			expected: "[Board](https://example.com/board)\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := adf2md.ParseADF(tt.input)
+			if err != nil {
+				t.Fatalf("Failed to parse ADF: %v", err)
+			}
+
+			renderer := adf2md.NewRenderer().WithOptions(adf2md.RenderOptions{ListIndent: 2, CardStyle: tt.style})
+			result, err := renderer.RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package adf2md
+
+import (
+	"strconv"
+	"time"
+)
+
+// DateStyle controls how dates (date nodes, including task due dates) are
+// written
+type DateStyle int
+
+const (
+	// DateStylePlain writes the date as text in RenderOptions.DateLayout
+	DateStylePlain DateStyle = iota
+	// DateStyleISO writes the date as an ISO 8601 timestamp such as
+	// 2023-11-14T00:00:00Z
+	DateStyleISO
+	// DateStyleHTML writes an HTML <time> element with the ISO 8601
+	// timestamp as its datetime attribute and the date in DateLayout as its
+	// text
+	DateStyleHTML
+)
+
+// DefaultDateLayout is the layout dates are written in when
+// RenderOptions.DateLayout is empty
+const DefaultDateLayout = "2006-01-02"
+
+// renderDate renders a date node in the configured DateStyle
+func (r *Renderer) renderDate(w *markdownWriter, node *Node) {
+	t, text, ok := r.options.formatDate(w.state, node)
+	if !ok {
+		w.WriteString(text)
+		return
+	}
+	if !r.options.DisableEscaping {
+		text = escapeText(text, escapeInline)
+	}
+
+	switch r.options.DateStyle {
+	case DateStyleISO:
+		w.WriteString(t.Format(time.RFC3339))
+	case DateStyleHTML:
+		w.WriteString(`<time datetime="` + t.Format(time.RFC3339) + `">` + text + "</time>")
+	default:
+		w.WriteString(text)
+	}
+}
+
+// formatDate reads the timestamp of a date node, which holds milliseconds
+// since the Unix epoch, and formats it in the configured layout and time
+// zone. Dates are shown in UTC, which Atlassian stores them in, unless
+// DateLocation is set. If the timestamp is missing or invalid, a warning is
+// recorded and the text is a placeholder instead.
+func (o *RenderOptions) formatDate(s *renderState, node *Node) (time.Time, string, bool) {
+	timestamp, ok := node.Attrs["timestamp"].(string)
+	if !ok {
+		s.warn(WarningMissingAttr, "date has no timestamp")
+		return time.Time{}, "[Date]", false
+	}
+
+	millis, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		s.warn(WarningLossy, "date timestamp "+strconv.Quote(timestamp)+" is not a number")
+		return time.Time{}, "[Date: " + timestamp + "]", false
+	}
+
+	location := o.DateLocation
+	if location == nil {
+		location = time.UTC
+	}
+	layout := o.DateLayout
+	if layout == "" {
+		layout = DefaultDateLayout
+	}
+
+	t := time.UnixMilli(millis).In(location)
+	return t, t.Format(layout), true
+}
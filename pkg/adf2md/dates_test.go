@@ -0,0 +1,68 @@
+package adf2md_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestRenderDates(t *testing.T) {
+	input := `{"version":1,"type":"doc","content":[
+		{"type":"paragraph","content":[{"type":"text","text":"Released "},{"type":"date","attrs":{"timestamp":"1731542400000"}}]},
+		{"type":"taskList","attrs":{"localId":"tasks"},"content":[
+			{"type":"taskItem","attrs":{"localId":"1","state":"TODO"},"content":[{"type":"text","text":"Ship it by "},{"type":"date","attrs":{"timestamp":"1731542400000"}}]}
+		]}
+	]}`
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("Time zone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		options  adf2md.RenderOptions
+		expected string
+	}{
+		{
+			name:     "Default layout",
+			options:  adf2md.RenderOptions{},
+			expected: "Released 2024-11-14\n\n- [ ] Ship it by 2024-11-14\n",
+		},
+		{
+			name:     "Custom layout and time zone",
+			options:  adf2md.RenderOptions{DateLayout: "Jan 2, 2006 15:04 MST", DateLocation: tokyo},
+			expected: "Released Nov 14, 2024 09:00 JST\n\n- [ ] Ship it by Nov 14, 2024 09:00 JST\n",
+		},
+		{
+			name:     "ISO 8601",
+			options:  adf2md.RenderOptions{DateStyle: adf2md.DateStyleISO, DateLocation: tokyo},
+			expected: "Released 2024-11-14T09:00:00+09:00\n\n- [ ] Ship it by 2024-11-14T09:00:00+09:00\n",
+		},
+		{
+			name:     "HTML time element",
+			options:  adf2md.RenderOptions{DateStyle: adf2md.DateStyleHTML, DateLayout: "2 January 2006"},
+			expected: "Released <time datetime=\"2024-11-14T00:00:00Z\">14 November 2024</time>\n\n- [ ] Ship it by <time datetime=\"2024-11-14T00:00:00Z\">14 November 2024</time>\n",
+		},
+	}
+
+	node, err := adf2md.ParseADF(input)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := adf2md.NewRenderer().WithOptions(tt.options)
+			result, err := renderer.RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package adf2md
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// escapeContext describes where a piece of text ends up in the Markdown
+// output, which decides which characters need escaping
+type escapeContext int
+
+const (
+	// escapeInline is ordinary running text
+	escapeInline escapeContext = iota
+	// escapeLinkLabel is text between the brackets of a link
+	escapeLinkLabel
+)
+
+var (
+	// entityRef matches an HTML entity or numeric character reference
+	entityRef = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+
+	// blockMarker matches text at the start of a line that Markdown would
+	// read as a heading, blockquote, bullet, setext underline or thematic break
+	blockMarker = regexp.MustCompile(`(?m)^( {0,3})(#{1,6}(?:[ \t]|$)|>|[-+](?:[ \t]|$)|=+[ \t]*$|-+[ \t]*$)`)
+
+	// orderedMarker matches text at the start of a line that Markdown would
+	// read as an ordered list item
+	orderedMarker = regexp.MustCompile(`(?m)^( {0,3}[0-9]{1,9})([.)](?:[ \t]|$))`)
+
+	// closingHashes matches a trailing run of # that Markdown would strip
+	// from the end of an ATX heading
+	closingHashes = regexp.MustCompile(`(^|[ \t])(#+[ \t]*)$`)
+)
+
+// escapeText escapes the characters in text that would otherwise be read as
+// inline Markdown syntax in the given context
+func escapeText(text string, ctx escapeContext) string {
+	if !strings.ContainsAny(text, "\\*`~[]_<&") {
+		return text
+	}
+
+	runes := []rune(text)
+
+	var result strings.Builder
+	for i, c := range runes {
+		switch c {
+		case '\\', '*', '`', '~', '[':
+			result.WriteRune('\\')
+		case ']':
+			// A lone ] is harmless in running text but would close a link label
+			if ctx == escapeLinkLabel {
+				result.WriteRune('\\')
+			}
+		case '_':
+			// Underscores inside a word can't open or close emphasis
+			if i == 0 || i == len(runes)-1 || !isWordRune(runes[i-1]) || !isWordRune(runes[i+1]) {
+				result.WriteRune('\\')
+			}
+		case '<':
+			// Only escape when it could start an HTML tag or autolink
+			if i+1 < len(runes) && (unicode.IsLetter(runes[i+1]) || strings.ContainsRune("/!?", runes[i+1])) {
+				result.WriteRune('\\')
+			}
+		case '&':
+			if entityRef.MatchString(string(runes[i:])) {
+				result.WriteRune('\\')
+			}
+		}
+		result.WriteRune(c)
+	}
+
+	return result.String()
+}
+
+// escapeLineStarts escapes block syntax, such as "# " or "1. " typed as
+// plain text, at the start of each line of text. The first line is only
+// escaped if the text is written at the start of a line.
+func escapeLineStarts(text string, atLineStart bool) string {
+	if !atLineStart {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 {
+			return text
+		}
+		return text[:i+1] + escapeLineStarts(text[i+1:], true)
+	}
+
+	// Most text can't start a block, so skip the regular expressions
+	if !strings.Contains(text, "\n") && !mayStartBlock(text) {
+		return text
+	}
+
+	text = blockMarker.ReplaceAllString(text, `${1}\${2}`)
+	return orderedMarker.ReplaceAllString(text, `${1}\${2}`)
+}
+
+// mayStartBlock reports whether a line begins with a character that could
+// start block syntax
+func mayStartBlock(line string) bool {
+	line = strings.TrimLeft(line, " ")
+	return line != "" && (strings.IndexByte("#>-+=", line[0]) >= 0 || line[0] >= '0' && line[0] <= '9')
+}
+
+// escapeHeading escapes a trailing run of # in heading content, which
+// Markdown would otherwise treat as an optional closing sequence
+func escapeHeading(content string) string {
+	return closingHashes.ReplaceAllString(content, `${1}\${2}`)
+}
+
+// escapeTableCell escapes pipes in rendered cell content so they don't
+// split the cell. This also covers pipes inside code spans, which GFM
+// requires to be escaped within tables.
+func escapeTableCell(content string) string {
+	return strings.ReplaceAll(content, "|", "\\|")
+}
+
+// codeSpanDelimiters returns the opening and closing delimiters of a code
+// span for text, using a backtick fence longer than any run of backticks in
+// the text
+func codeSpanDelimiters(text string) (string, string) {
+	if !strings.Contains(text, "`") {
+		return "`", "`"
+	}
+
+	longest, run := 0, 0
+	for _, c := range text {
+		if c == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+
+	fence := strings.Repeat("`", longest+1)
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		return fence + " ", " " + fence
+	}
+	return fence, fence
+}
+
+// linkDestination formats a URL for use in a Markdown link or image,
+// wrapping it in angle brackets when it contains spaces or parentheses
+func linkDestination(url string) string {
+	if !strings.ContainsAny(url, " ()<>") {
+		return url
+	}
+	url = strings.ReplaceAll(url, "<", "%3C")
+	url = strings.ReplaceAll(url, ">", "%3E")
+	return "<" + url + ">"
+}
+
+// isWordRune reports whether c is a letter or digit
+func isWordRune(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c)
+}
@@ -0,0 +1,120 @@
+package adf2md_test
+
+import (
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestEscaping(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Inline emphasis characters",
+			input:    `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"2 * 3 and _init_ and ~~old~~"}]}]}`,
+			expected: "2 \\* 3 and \\_init\\_ and \\~\\~old\\~\\~\n\n",
+		},
+		{
+			name:     "Intraword underscores are left alone",
+			input:    `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"snake_case_name"}]}]}`,
+			expected: "snake_case_name\n\n",
+		},
+		{
+			name:     "Brackets, backticks and HTML",
+			input:    `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"[x] use ` + "`" + `go` + "`" + ` not <div> & a < b &amp;"}]}]}`,
+			expected: "\\[x] use \\`go\\` not \\<div> & a < b \\&amp;\n\n",
+		},
+		{
+			name:     "Heading marker at line start",
+			input:    `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"# not a heading"}]}]}`,
+			expected: "\\# not a heading\n\n",
+		},
+		{
+			name:     "Ordered list marker at line start",
+			input:    `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"1. not a list"}]}]}`,
+			expected: "1\\. not a list\n\n",
+		},
+		{
+			name:     "Block markers after a hard break",
+			input:    `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"intro"},{"type":"hardBreak"},{"type":"text","text":"- not a bullet"},{"type":"hardBreak"},{"type":"text","text":"> not a quote"},{"type":"hardBreak"},{"type":"text","text":"---"}]}]}`,
+			expected: "intro  \n\\- not a bullet  \n\\> not a quote  \n\\---\n\n",
+		},
+		{
+			name:     "Markers mid-line are left alone",
+			input:    `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"Step 1. then - and # and >"}]}]}`,
+			expected: "Step 1. then - and # and >\n\n",
+		},
+		{
+			name:     "Trailing hashes in a heading",
+			input:    `{"version":1,"type":"doc","content":[{"type":"heading","attrs":{"level":2},"content":[{"type":"text","text":"Issue #"}]}]}`,
+			expected: "## Issue \\#\n\n",
+		},
+		{
+			name:     "Link label and destination",
+			input:    `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"see [docs]","marks":[{"type":"link","attrs":{"href":"https://example.com/a b"}}]}]}]}`,
+			expected: "[see \\[docs\\]](<https://example.com/a b>)\n\n",
+		},
+		{
+			name:     "Code spans are not escaped",
+			input:    `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"a_b *c*","marks":[{"type":"code"}]}]}]}`,
+			expected: "`a_b *c*`\n\n",
+		},
+		{
+			name:     "Code spans containing backticks",
+			input:    `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"a` + "`" + `b","marks":[{"type":"code"}]}]}]}`,
+			expected: "``a`b``\n\n",
+		},
+		{
+			name:     "Pipes in table cells",
+			input:    `{"version":1,"type":"doc","content":[{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"a|b "},{"type":"text","text":"c|d","marks":[{"type":"code"}]}]}]}]}]}]}`,
+			expected: "| a\\|b `c\\|d` |\n| --- |\n\n",
+		},
+	}
+
+	renderer := adf2md.NewRenderer()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := adf2md.ParseADF(tt.input)
+			if err != nil {
+				t.Fatalf("Failed to parse ADF: %v", err)
+			}
+
+			result, err := renderer.RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDisableEscaping(t *testing.T) {
+	input := `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"# *raw* [text]"}]}]}`
+
+	node, err := adf2md.ParseADF(input)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	renderer := adf2md.NewRenderer().WithOptions(adf2md.RenderOptions{
+		ListIndent:      2,
+		DisableEscaping: true,
+	})
+
+	result, err := renderer.RenderToMarkdown(node)
+	if err != nil {
+		t.Fatalf("RenderToMarkdown failed: %v", err)
+	}
+
+	expected := "# *raw* [text]\n\n"
+	if result != expected {
+		t.Errorf("\nExpected: %q\nGot:      %q", expected, result)
+	}
+}
@@ -245,4 +245,29 @@ func ExampleRenderer_RenderToMarkdown() {
 	fmt.Print(markdown)
 
 	// No Output defined here to skip the example test
-}
\ No newline at end of file
+}
+func ExampleRenderer_Register() {
+	node, err := adf2md.ParseADF(`{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"Ping "},{"type":"mention","attrs":{"id":"5b10ac8d82e05b22cc7d4ef5","text":"Jane"}}]}]}`)
+	if err != nil {
+		fmt.Printf("Error parsing ADF: %v\n", err)
+		return
+	}
+
+	// Render mentions as Slack-style user references
+	renderer := adf2md.NewRenderer().Register("mention", adf2md.NodeRendererFunc(
+		func(ctx *adf2md.RenderContext, node *adf2md.Node) error {
+			id, _ := node.Attrs["id"].(string)
+			ctx.WriteString("<@" + id + ">")
+			return nil
+		},
+	))
+
+	markdown, err := renderer.RenderToMarkdown(node)
+	if err != nil {
+		fmt.Printf("Error rendering Markdown: %v\n", err)
+		return
+	}
+
+	fmt.Print(markdown)
+	// Output: Ping <@5b10ac8d82e05b22cc7d4ef5>
+}
@@ -0,0 +1,38 @@
+package adf2md
+
+import "html"
+
+// renderExpand renders an expand or nestedExpand node as a collapsible
+// HTML details block, or as a bold title followed by the content when
+// FlattenExpands is set
+func (r *Renderer) renderExpand(w *markdownWriter, node *Node) {
+	title, _ := node.Attrs["title"].(string)
+
+	if r.options.FlattenExpands {
+		if title != "" {
+			if !r.options.DisableEscaping {
+				title = escapeText(title, escapeInline)
+			}
+			w.WriteString("**" + title + "**")
+			w.ensureNewlines(2)
+		}
+		r.renderContent(w, node.Content)
+		w.ensureNewlines(2)
+		return
+	}
+
+	w.WriteString("<details>")
+	if title != "" {
+		// The summary is part of the HTML block, so Markdown isn't parsed in it
+		w.ensureNewlines(1)
+		w.WriteString("<summary>" + html.EscapeString(title) + "</summary>")
+	}
+
+	// Blank lines around the content end the HTML block so it is read as
+	// Markdown
+	w.ensureNewlines(2)
+	r.renderContent(w, node.Content)
+	w.ensureNewlines(2)
+	w.WriteString("</details>")
+	w.ensureNewlines(2)
+}
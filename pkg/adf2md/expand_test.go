@@ -0,0 +1,67 @@
+package adf2md_test
+
+import (
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestRenderExpand(t *testing.T) {
+	expand := `{"version":1,"type":"doc","content":[{"type":"expand","attrs":{"title":"Details <v2>"},"content":[{"type":"paragraph","content":[{"type":"text","text":"Hidden "},{"type":"text","text":"text","marks":[{"type":"strong"}]}]},{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"item"}]}]}]}]}]}`
+	nested := `{"version":1,"type":"doc","content":[{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableCell","content":[{"type":"nestedExpand","attrs":{"title":"More"},"content":[{"type":"paragraph","content":[{"type":"text","text":"Inside"}]}]}]}]}]}]}`
+	untitled := `{"version":1,"type":"doc","content":[{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"Item"}]},{"type":"expand","content":[{"type":"paragraph","content":[{"type":"text","text":"Body"}]}]}]}]}]}`
+
+	tests := []struct {
+		name     string
+		flatten  bool
+		input    string
+		expected string
+	}{
+		{
+			name:     "Details block",
+			input:    expand,
+			expected: "<details>\n<summary>Details &lt;v2&gt;</summary>\n\nHidden **text**\n\n* item\n\n</details>\n\n",
+		},
+		{
+			name:     "Nested expand in a table cell",
+			input:    nested,
+			expected: "<table>\n<tr>\n<td>\n\n<details>\n<summary>More</summary>\n\nInside\n\n</details>\n\n</td>\n</tr>\n</table>\n\n",
+		},
+		{
+			name:     "Untitled expand in a list",
+			input:    untitled,
+			expected: "* Item\n  <details>\n\n  Body\n\n  </details>\n",
+		},
+		{
+			name:     "Flattened",
+			flatten:  true,
+			input:    expand,
+			expected: "**Details \\<v2>**\n\nHidden **text**\n\n* item\n\n",
+		},
+		{
+			name:     "Flattened without a title",
+			flatten:  true,
+			input:    untitled,
+			expected: "* Item\n  Body\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := adf2md.ParseADF(tt.input)
+			if err != nil {
+				t.Fatalf("Failed to parse ADF: %v", err)
+			}
+
+			renderer := adf2md.NewRenderer().WithOptions(adf2md.RenderOptions{ListIndent: 2, FlattenExpands: tt.flatten})
+			result, err := renderer.RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
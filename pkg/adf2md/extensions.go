@@ -0,0 +1,129 @@
+package adf2md
+
+// Extension describes an extension node, such as a Confluence macro, for
+// an ExtensionHandler to render
+type Extension struct {
+	// The extension node itself
+	Node *Node
+	// The extensionType attribute, e.g. "com.atlassian.confluence.macro.core"
+	Type string
+	// The extensionKey attribute, e.g. "toc" or "jira"
+	Key string
+	// The parameters attribute, which holds the macro's settings
+	Parameters map[string]any
+	// Whether the extension appears within a line of text
+	// (inlineExtension) rather than as a block
+	Inline bool
+	// The rendered Markdown of each body: one for a bodiedExtension, one
+	// per frame for a multiBodiedExtension and none for other extensions
+	Bodies []string
+}
+
+// Title returns a human readable name for the extension, taken from the
+// macro metadata if the document has it, or else the extension key
+func (e *Extension) Title() string {
+	if metadata, ok := e.Parameters["macroMetadata"].(map[string]any); ok {
+		if title, ok := metadata["title"].(string); ok && title != "" {
+			return title
+		}
+	}
+	if text, ok := e.Node.Attrs["text"].(string); ok && text != "" {
+		return text
+	}
+	return e.Key
+}
+
+// ExtensionHandler renders extension nodes. Handlers are registered on a
+// Renderer with RegisterExtension for an extension type and key.
+type ExtensionHandler interface {
+	// RenderExtension writes the output for ext through ctx. Returning an
+	// error stops rendering and is returned to the caller.
+	RenderExtension(ctx *RenderContext, ext *Extension) error
+}
+
+// ExtensionHandlerFunc adapts an ordinary function to the ExtensionHandler
+// interface
+type ExtensionHandlerFunc func(ctx *RenderContext, ext *Extension) error
+
+// RenderExtension calls f(ctx, ext)
+func (f ExtensionHandlerFunc) RenderExtension(ctx *RenderContext, ext *Extension) error {
+	return f(ctx, ext)
+}
+
+// DefaultExtensionHandler renders the bodies of bodied extensions as they
+// are, and any other extension as a placeholder naming the macro
+var DefaultExtensionHandler ExtensionHandler = ExtensionHandlerFunc(func(ctx *RenderContext, ext *Extension) error {
+	switch ext.Node.Type {
+	case "bodiedExtension", "multiBodiedExtension":
+		for _, body := range ext.Bodies {
+			ctx.EnsureNewlines(2)
+			ctx.WriteString(body)
+		}
+	default:
+		ctx.Warn(WarningLossy, "extension "+ext.Key+" written as a placeholder")
+		ctx.WriteString(ctx.renderer.placeholder("Macro: " + ctx.EscapeText(ext.Title())))
+	}
+	return nil
+})
+
+// RegisterExtension sets the handler for extensions with the given
+// extensionType and extensionKey. An empty key registers the handler for
+// every key of the type that has no handler of its own. Registering nil
+// removes the handler.
+func (r *Renderer) RegisterExtension(extensionType, extensionKey string, handler ExtensionHandler) *Renderer {
+	key := extensionType + "/" + extensionKey
+	if handler == nil {
+		delete(r.extensionHandlers, key)
+		return r
+	}
+
+	if r.extensionHandlers == nil {
+		r.extensionHandlers = make(map[string]ExtensionHandler)
+	}
+	r.extensionHandlers[key] = handler
+	return r
+}
+
+// extensionHandler returns the handler registered for an extension, or
+// DefaultExtensionHandler if there is none
+func (r *Renderer) extensionHandler(extensionType, extensionKey string) ExtensionHandler {
+	if handler, ok := r.extensionHandlers[extensionType+"/"+extensionKey]; ok {
+		return handler
+	}
+	if handler, ok := r.extensionHandlers[extensionType+"/"]; ok {
+		return handler
+	}
+	return DefaultExtensionHandler
+}
+
+// renderExtension renders an extension, inlineExtension, bodiedExtension
+// or multiBodiedExtension node with its ExtensionHandler
+func (r *Renderer) renderExtension(w *markdownWriter, node *Node) {
+	ext := &Extension{Node: node, Inline: node.Type == "inlineExtension"}
+	ext.Type, _ = node.Attrs["extensionType"].(string)
+	ext.Key, _ = node.Attrs["extensionKey"].(string)
+	ext.Parameters, _ = node.Attrs["parameters"].(map[string]any)
+
+	switch node.Type {
+	case "bodiedExtension":
+		ext.Bodies = []string{r.renderBlocks(w, node.Content)}
+	case "multiBodiedExtension":
+		for i := range node.Content {
+			frame := &node.Content[i]
+			if frame.Type != "extensionFrame" {
+				continue
+			}
+			w.state.enter(frame)
+			ext.Bodies = append(ext.Bodies, r.renderBlocks(w, frame.Content))
+			w.state.leave()
+		}
+	}
+
+	if err := r.extensionHandler(ext.Type, ext.Key).RenderExtension(r.context(w), ext); err != nil {
+		w.fail(err)
+	}
+
+	if !ext.Inline {
+		w.ensureNewlines(2)
+	}
+}
@@ -0,0 +1,86 @@
+package adf2md_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestRenderExtensions(t *testing.T) {
+	input := `{"version":1,"type":"doc","content":[
+		{"type":"extension","attrs":{"extensionType":"com.atlassian.confluence.macro.core","extensionKey":"toc","parameters":{"macroParams":{"maxLevel":{"value":"3"}},"macroMetadata":{"title":"Table of Contents"}}}},
+		{"type":"paragraph","content":[{"type":"text","text":"Status: "},{"type":"inlineExtension","attrs":{"extensionType":"com.atlassian.confluence.macro.core","extensionKey":"anchor"}}]},
+		{"type":"bodiedExtension","attrs":{"extensionType":"com.atlassian.confluence.macro.core","extensionKey":"excerpt"},"content":[{"type":"paragraph","content":[{"type":"text","text":"Summary"}]},{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"point"}]}]}]}]},
+		{"type":"multiBodiedExtension","attrs":{"extensionType":"com.atlassian.confluence.macro.core","extensionKey":"tabs"},"content":[
+			{"type":"extensionFrame","content":[{"type":"paragraph","content":[{"type":"text","text":"Tab one"}]}]},
+			{"type":"extensionFrame","content":[{"type":"paragraph","content":[{"type":"text","text":"Tab two"}]}]}
+		]}
+	]}`
+
+	tocHandler := adf2md.ExtensionHandlerFunc(func(ctx *adf2md.RenderContext, ext *adf2md.Extension) error {
+		params, _ := ext.Parameters["macroParams"].(map[string]any)
+		maxLevel, _ := params["maxLevel"].(map[string]any)
+		ctx.WriteString(fmt.Sprintf("<!-- toc maxLevel=%v -->", maxLevel["value"]))
+		return nil
+	})
+
+	tabsHandler := adf2md.ExtensionHandlerFunc(func(ctx *adf2md.RenderContext, ext *adf2md.Extension) error {
+		for i, body := range ext.Bodies {
+			ctx.EnsureNewlines(2)
+			ctx.WriteString(fmt.Sprintf("### Tab %d", i+1))
+			ctx.EnsureNewlines(2)
+			ctx.WriteString(body)
+		}
+		return nil
+	})
+
+	anyHandler := adf2md.ExtensionHandlerFunc(func(ctx *adf2md.RenderContext, ext *adf2md.Extension) error {
+		ctx.WriteString("{" + ext.Key + "}")
+		return nil
+	})
+
+	tests := []struct {
+		name     string
+		renderer *adf2md.Renderer
+		expected string
+	}{
+		{
+			name:     "Default handlers",
+			renderer: adf2md.NewRenderer(),
+			expected: "[Macro: Table of Contents]\n\nStatus: [Macro: anchor]\n\nSummary\n\n* point\n\nTab one\n\nTab two\n\n",
+		},
+		{
+			name: "Handlers by type and key",
+			renderer: adf2md.NewRenderer().
+				RegisterExtension("com.atlassian.confluence.macro.core", "toc", tocHandler).
+				RegisterExtension("com.atlassian.confluence.macro.core", "tabs", tabsHandler),
+			expected: "<!-- toc maxLevel=3 -->\n\nStatus: [Macro: anchor]\n\nSummary\n\n* point\n\n### Tab 1\n\nTab one\n\n### Tab 2\n\nTab two\n\n",
+		},
+		{
+			name: "Handler for every key of a type",
+			renderer: adf2md.NewRenderer().
+				RegisterExtension("com.atlassian.confluence.macro.core", "", anyHandler).
+				RegisterExtension("com.atlassian.confluence.macro.core", "excerpt", adf2md.DefaultExtensionHandler),
+			expected: "{toc}\n\nStatus: {anchor}\n\nSummary\n\n* point\n\n{tabs}\n\n",
+		},
+	}
+
+	node, err := adf2md.ParseADF(input)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.renderer.RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,729 @@
+package adf2md
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTMLOptions contains configuration for the HTML rendering
+type HTMLOptions struct {
+	// Options shared with the Markdown renderer: the mention and media
+	// resolvers, date layout and time zone, and Strict. Options that only
+	// apply to Markdown syntax are ignored.
+	RenderOptions
+
+	// Wrap the output in a complete HTML page with a minimal stylesheet
+	Standalone bool
+
+	// The title of a standalone page
+	Title string
+}
+
+// HTMLRenderer handles converting ADF nodes to HTML. Panels, status
+// lozenges and other Atlassian elements are written as elements with
+// classes such as "panel panel-info" and "status status-green", so they can
+// be styled to match.
+type HTMLRenderer struct {
+	// Options for customizing the HTML output
+	options HTMLOptions
+}
+
+// NewHTMLRenderer creates a new HTML renderer with default options
+func NewHTMLRenderer() *HTMLRenderer {
+	return &HTMLRenderer{}
+}
+
+// WithOptions returns a new HTMLRenderer with the specified options
+func (r *HTMLRenderer) WithOptions(options HTMLOptions) *HTMLRenderer {
+	r.options = options
+	return r
+}
+
+// RenderToHTML converts an ADF node to HTML
+func (r *HTMLRenderer) RenderToHTML(node *Node) (string, error) {
+	var result strings.Builder
+	if _, err := r.render(&result, node); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// Render converts an ADF node to HTML, writing the output to w as it is
+// produced rather than building it up in memory. In strict mode nothing is
+// written to w if the document contains unsupported nodes or marks.
+func (r *HTMLRenderer) Render(w io.Writer, node *Node) error {
+	out := bufio.NewWriter(w)
+	if _, err := r.render(out, node); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// render writes the HTML for a node to out and returns the warnings found
+// along the way
+func (r *HTMLRenderer) render(out io.Writer, node *Node) ([]Warning, error) {
+	if node == nil {
+		return nil, fmt.Errorf("nil node provided")
+	}
+
+	// Strict mode can only fail once the whole document has been seen, so
+	// the output is held back until then
+	var held bytes.Buffer
+	target := out
+	if r.options.Strict {
+		target = &held
+	}
+
+	w := newMarkdownWriter(target)
+	if r.options.Standalone {
+		r.writePageHeader(w)
+	}
+	r.renderNode(w, node)
+	if r.options.Standalone {
+		w.ensureNewlines(1)
+		w.WriteString("</body>\n</html>")
+	}
+	w.ensureNewlines(1)
+	if err := w.finish(); err != nil {
+		return nil, err
+	}
+
+	if r.options.Strict {
+		if unsupported := unsupported(w.state.warnings); len(unsupported) > 0 {
+			return nil, &UnsupportedError{Unsupported: unsupported}
+		}
+		if _, err := held.WriteTo(out); err != nil {
+			return nil, err
+		}
+	}
+	return w.state.warnings, nil
+}
+
+// htmlStylesheet is the minimal stylesheet included in standalone pages
+const htmlStylesheet = `body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; line-height: 1.5; max-width: 50em; margin: 2em auto; padding: 0 1em; color: #172b4d; }
+pre { background: #f4f5f7; padding: 0.75em; overflow-x: auto; }
+code { font-family: SFMono-Regular, Consolas, monospace; font-size: 0.9em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #dfe1e6; padding: 0.25em 0.5em; vertical-align: top; }
+th { background: #f4f5f7; text-align: left; }
+blockquote { border-left: 2px solid #dfe1e6; margin-left: 0; padding-left: 1em; color: #5e6c84; }
+figure { margin: 1em 0; }
+img { max-width: 100%; }
+.panel { padding: 0.5em 1em; margin: 1em 0; border-radius: 3px; background: #deebff; }
+.panel-note { background: #eae6ff; }
+.panel-warning { background: #fffae6; }
+.panel-error { background: #ffebe6; }
+.panel-success, .panel-tip { background: #e3fcef; }
+.status { display: inline-block; padding: 0 0.25em; border-radius: 3px; font-size: 0.8em; font-weight: bold; text-transform: uppercase; background: #dfe1e6; }
+.status-purple { background: #eae6ff; color: #403294; }
+.status-blue { background: #deebff; color: #0747a6; }
+.status-red { background: #ffebe6; color: #bf2600; }
+.status-yellow { background: #fff0b3; color: #172b4d; }
+.status-green { background: #e3fcef; color: #006644; }
+.mention { background: #ebecf0; border-radius: 1em; padding: 0 0.3em; }
+.task-list, .decision-list { list-style: none; padding-left: 1em; }
+.layout-section { display: flex; gap: 1em; }
+.layout-column { flex: 1 1 0; }`
+
+// writePageHeader starts a standalone page
+func (r *HTMLRenderer) writePageHeader(w *markdownWriter) {
+	w.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	w.WriteString("<title>" + htmlText(r.options.Title) + "</title>\n")
+	w.WriteString("<style>\n" + htmlStylesheet + "\n</style>\n</head>\n<body>")
+	w.ensureNewlines(1)
+}
+
+// renderNode writes the HTML representation of a single ADF node
+func (r *HTMLRenderer) renderNode(w *markdownWriter, node *Node) {
+	if node == nil || w.err != nil {
+		return
+	}
+
+	w.state.enter(node)
+	defer w.state.leave()
+
+	switch node.Type {
+	case "doc":
+		r.renderBlocks(w, node.Content)
+	case "paragraph":
+		r.renderElement(w, "p", "", node.Content)
+	case "text":
+		r.renderText(w, node)
+	case "heading":
+		r.renderHeading(w, node)
+	case "bulletList":
+		r.renderList(w, "ul", "", node)
+	case "orderedList":
+		attrs := ""
+		if order, ok := intAttr(node.Attrs, "order"); ok && order != 1 {
+			attrs = ` start="` + strconv.Itoa(order) + `"`
+		}
+		r.renderList(w, "ol", attrs, node)
+	case "taskList":
+		r.renderList(w, "ul", ` class="task-list"`, node)
+	case "decisionList":
+		r.renderList(w, "ul", ` class="decision-list"`, node)
+	case "listItem", "taskItem", "decisionItem":
+		r.renderListItem(w, node)
+	case "codeBlock":
+		r.renderCodeBlock(w, node)
+	case "rule":
+		r.renderBlock(w, "<hr>")
+	case "blockquote":
+		r.renderContainer(w, "blockquote", "", node.Content)
+	case "hardBreak":
+		w.WriteString("<br>")
+	case "panel":
+		r.renderPanel(w, node)
+	case "mention":
+		r.renderMention(w, node)
+	case "emoji":
+		r.renderEmoji(w, node)
+	case "date":
+		r.renderDate(w, node)
+	case "status":
+		r.renderStatus(w, node)
+	case "mediaSingle":
+		r.renderContainer(w, "figure", "", node.Content)
+	case "media":
+		r.renderMedia(w, node)
+	case "mediaGroup":
+		r.renderMediaGroup(w, node)
+	case "mediaInline":
+		r.renderMediaLink(w, node)
+	case "caption":
+		r.renderElement(w, "figcaption", "", node.Content)
+	case "inlineCard", "blockCard", "embedCard":
+		r.renderCard(w, node)
+	case "expand", "nestedExpand":
+		r.renderExpand(w, node)
+	case "layoutSection":
+		r.renderContainer(w, "div", ` class="layout-section"`, node.Content)
+	case "layoutColumn":
+		attrs := ` class="layout-column"`
+		if width, ok := columnWidth(node); ok {
+			attrs += ` style="flex-basis: ` + width + `"`
+		}
+		r.renderContainer(w, "div", attrs, node.Content)
+	case "extension", "inlineExtension", "bodiedExtension", "multiBodiedExtension":
+		r.renderExtension(w, node)
+	case "extensionFrame":
+		r.renderContainer(w, "div", ` class="extension-frame"`, node.Content)
+	case "table":
+		r.renderTable(w, node)
+	case "tableRow":
+		r.renderContainer(w, "tr", "", node.Content)
+	case "tableHeader", "tableCell":
+		r.renderTableCell(w, node)
+	default:
+		w.state.warn(WarningUnknownNode, "unsupported node "+strconv.Quote(node.Type)+" written as a placeholder")
+		w.WriteString(`<span class="unsupported">[Unsupported ADF Element: ` + htmlText(node.Type) + "]</span>")
+	}
+}
+
+// renderBlocks renders block content, each block on its own line
+func (r *HTMLRenderer) renderBlocks(w *markdownWriter, nodes []Node) {
+	for i := range nodes {
+		w.ensureNewlines(1)
+		r.renderNode(w, &nodes[i])
+	}
+}
+
+// renderInline renders inline content in place
+func (r *HTMLRenderer) renderInline(w *markdownWriter, nodes []Node) {
+	for i := range nodes {
+		r.renderNode(w, &nodes[i])
+	}
+}
+
+// renderBlock writes a complete block-level element on its own line
+func (r *HTMLRenderer) renderBlock(w *markdownWriter, element string) {
+	w.ensureNewlines(1)
+	w.WriteString(element)
+	w.ensureNewlines(1)
+}
+
+// renderElement renders inline content inside a block-level element
+func (r *HTMLRenderer) renderElement(w *markdownWriter, tag, attrs string, content []Node) {
+	w.ensureNewlines(1)
+	w.WriteString("<" + tag + attrs + ">")
+	r.renderInline(w, content)
+	w.WriteString("</" + tag + ">")
+	w.ensureNewlines(1)
+}
+
+// renderContainer renders block content inside an element, with the tags
+// on their own lines
+func (r *HTMLRenderer) renderContainer(w *markdownWriter, tag, attrs string, content []Node) {
+	w.ensureNewlines(1)
+	w.WriteString("<" + tag + attrs + ">")
+	r.renderBlocks(w, content)
+	w.ensureNewlines(1)
+	w.WriteString("</" + tag + ">")
+	w.ensureNewlines(1)
+}
+
+// renderFlow renders the content of a list item or table cell. A lone
+// paragraph is written without its <p> so simple lists and tables stay
+// compact.
+func (r *HTMLRenderer) renderFlow(w *markdownWriter, content []Node) {
+	if len(content) == 1 && content[0].Type == "paragraph" {
+		w.state.enter(&content[0])
+		r.renderInline(w, content[0].Content)
+		w.state.leave()
+		return
+	}
+	if hasBlockNode(content) {
+		r.renderBlocks(w, content)
+		w.ensureNewlines(1)
+		return
+	}
+	r.renderInline(w, content)
+}
+
+// hasBlockNode reports whether content holds any block nodes, as opposed to
+// the inline content of task and decision items
+func hasBlockNode(content []Node) bool {
+	for _, node := range content {
+		if !contains(inlineNodes, node.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderText renders a text node with its marks as HTML elements
+func (r *HTMLRenderer) renderText(w *markdownWriter, node *Node) {
+	text := htmlText(node.Text)
+
+	marks := orderMarks(node.Marks)
+	for i := len(marks) - 1; i >= 0; i-- {
+		open, close, ok := r.markTags(w, &marks[i])
+		if !ok {
+			w.state.warnMark(WarningUnknownMark, &marks[i], "unsupported mark "+strconv.Quote(marks[i].Type)+" dropped")
+			continue
+		}
+		text = open + text + close
+	}
+	w.WriteString(text)
+}
+
+// markTags returns the HTML tags to write around text with a mark
+func (r *HTMLRenderer) markTags(w *markdownWriter, mark *Mark) (open, close string, ok bool) {
+	switch mark.Type {
+	case "strong":
+		return "<strong>", "</strong>", true
+	case "em":
+		return "<em>", "</em>", true
+	case "code":
+		return "<code>", "</code>", true
+	case "strike":
+		return "<s>", "</s>", true
+	case "underline":
+		return "<u>", "</u>", true
+	case "subsup":
+		if kind, _ := mark.Attrs["type"].(string); kind == "sup" {
+			return "<sup>", "</sup>", true
+		}
+		return "<sub>", "</sub>", true
+	case "link":
+		href, _ := mark.Attrs["href"].(string)
+		attrs := htmlHref(href)
+		if attrs == "" {
+			w.state.warnMark(WarningLossy, mark, "link to "+strconv.Quote(href)+" dropped")
+		}
+		if title, _ := mark.Attrs["title"].(string); title != "" {
+			attrs += ` title="` + html.EscapeString(title) + `"`
+		}
+		return "<a" + attrs + ">", "</a>", true
+	case "textColor", "backgroundColor":
+		property := "color"
+		if mark.Type == "backgroundColor" {
+			property = "background-color"
+		}
+		color, _ := mark.Attrs["color"].(string)
+		if !isCSSColor(color) {
+			w.state.warnMark(WarningLossy, mark, "color "+strconv.Quote(color)+" dropped")
+			return "", "", true
+		}
+		return `<span style="` + property + ": " + color + `">`, "</span>", true
+	}
+	return "", "", false
+}
+
+// renderHeading renders a heading node
+func (r *HTMLRenderer) renderHeading(w *markdownWriter, node *Node) {
+	level, ok := intAttr(node.Attrs, "level")
+	if !ok {
+		w.state.warn(WarningMissingAttr, "heading has no level, using 1")
+	}
+	level = max(1, min(level, 6))
+	r.renderElement(w, "h"+strconv.Itoa(level), "", node.Content)
+}
+
+// renderList renders a list element whose children become list items
+func (r *HTMLRenderer) renderList(w *markdownWriter, tag, attrs string, node *Node) {
+	w.ensureNewlines(1)
+	w.WriteString("<" + tag + attrs + ">")
+	for i := range node.Content {
+		item := &node.Content[i]
+		w.ensureNewlines(1)
+		switch item.Type {
+		case "listItem", "taskItem", "decisionItem":
+			r.renderNode(w, item)
+		default:
+			// Nested task and decision lists have no item of their own
+			w.WriteString("<li>")
+			r.renderNode(w, item)
+			w.WriteString("</li>")
+		}
+	}
+	w.ensureNewlines(1)
+	w.WriteString("</" + tag + ">")
+	w.ensureNewlines(1)
+}
+
+// renderListItem renders a list, task or decision item
+func (r *HTMLRenderer) renderListItem(w *markdownWriter, node *Node) {
+	state, _ := node.Attrs["state"].(string)
+	switch node.Type {
+	case "taskItem":
+		checked := ""
+		if state == "DONE" {
+			checked = " checked"
+		}
+		w.WriteString(`<li class="task-item"><input type="checkbox" disabled` + checked + "> ")
+	case "decisionItem":
+		class := "decision-item"
+		if state == "DECIDED" {
+			class += " decided"
+		}
+		w.WriteString(`<li class="` + class + `">`)
+	default:
+		w.WriteString("<li>")
+	}
+	r.renderFlow(w, node.Content)
+	w.WriteString("</li>")
+}
+
+// renderCodeBlock renders a code block node
+func (r *HTMLRenderer) renderCodeBlock(w *markdownWriter, node *Node) {
+	attrs := ""
+	if language, _ := node.Attrs["language"].(string); language != "" {
+		attrs = ` class="language-` + html.EscapeString(language) + `"`
+	}
+
+	var code strings.Builder
+	for _, child := range node.Content {
+		code.WriteString(child.Text)
+	}
+	r.renderBlock(w, "<pre><code"+attrs+">"+htmlText(code.String())+"</code></pre>")
+}
+
+// renderPanel renders a panel node as a div with the panel type as a class.
+// Custom panels keep their color and icon.
+func (r *HTMLRenderer) renderPanel(w *markdownWriter, node *Node) {
+	panelType, _ := node.Attrs["panelType"].(string)
+	if panelType == "" {
+		panelType = "info"
+	}
+	attrs := ` class="panel panel-` + html.EscapeString(panelType) + `"`
+
+	if panelType == "custom" {
+		if color, _ := node.Attrs["panelColor"].(string); isCSSColor(color) {
+			attrs += ` style="background-color: ` + color + `"`
+		}
+	}
+
+	w.ensureNewlines(1)
+	w.WriteString("<div" + attrs + ">")
+	if icon := panelIcon(node); panelType == "custom" && icon != "" {
+		w.ensureNewlines(1)
+		w.WriteString(`<span class="panel-icon">` + htmlText(icon) + "</span>")
+	}
+	r.renderBlocks(w, node.Content)
+	w.ensureNewlines(1)
+	w.WriteString("</div>")
+	w.ensureNewlines(1)
+}
+
+// renderMention renders a mention node, linking to the user's profile if
+// the MentionResolver knows it
+func (r *HTMLRenderer) renderMention(w *markdownWriter, node *Node) {
+	user := r.options.mentionUser(node)
+	if user.Name == "" && user.ID == "" {
+		w.state.warn(WarningMissingAttr, "mention has no text or id")
+	}
+
+	name := user.Name
+	if name == "" {
+		name = "user:" + user.ID
+	}
+	attrs := ` class="mention"`
+	if user.ID != "" {
+		attrs += ` data-account-id="` + html.EscapeString(user.ID) + `"`
+	}
+
+	if href := htmlHref(user.URL); user.URL != "" && href != "" {
+		w.WriteString("<a" + attrs + href + ">@" + htmlText(name) + "</a>")
+		return
+	}
+	w.WriteString("<span" + attrs + ">@" + htmlText(name) + "</span>")
+}
+
+// renderEmoji renders an emoji node
+func (r *HTMLRenderer) renderEmoji(w *markdownWriter, node *Node) {
+	text, _ := node.Attrs["text"].(string)
+	shortName, _ := node.Attrs["shortName"].(string)
+	if text == "" && shortName == "" {
+		w.state.warn(WarningMissingAttr, "emoji has no text or shortName")
+		return
+	}
+	if text == "" {
+		text = shortName
+	}
+	w.WriteString(`<span class="emoji" title="` + html.EscapeString(shortName) + `">` + htmlText(text) + "</span>")
+}
+
+// renderDate renders a date node as a <time> element
+func (r *HTMLRenderer) renderDate(w *markdownWriter, node *Node) {
+	t, text, ok := r.options.formatDate(w.state, node)
+	if !ok {
+		w.WriteString(htmlText(text))
+		return
+	}
+	w.WriteString(`<time datetime="` + t.Format(time.RFC3339) + `">` + htmlText(text) + "</time>")
+}
+
+// renderStatus renders a status node as a span with its color as a class
+func (r *HTMLRenderer) renderStatus(w *markdownWriter, node *Node) {
+	text, ok := node.Attrs["text"].(string)
+	if !ok {
+		w.state.warn(WarningMissingAttr, "status has no text")
+		text = "STATUS"
+	}
+	color, _ := node.Attrs["color"].(string)
+	if _, ok := statusColors[color]; !ok {
+		color = "neutral"
+	}
+	w.WriteString(`<span class="status status-` + color + `">` + htmlText(text) + "</span>")
+}
+
+// renderMedia renders a media node as an image
+func (r *HTMLRenderer) renderMedia(w *markdownWriter, node *Node) {
+	alt, _ := node.Attrs["alt"].(string)
+
+	src, ok := r.options.mediaURL(node)
+	if !ok || htmlHref(src) == "" {
+		w.state.warn(WarningUnresolvedMedia, "media has no URL or id to link to")
+		if alt == "" {
+			alt = "image"
+		}
+		w.WriteString(`<span class="media-unresolved">[Image: ` + htmlText(alt) + "]</span>")
+		return
+	}
+
+	attrs := ` src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(alt) + `"`
+	if width, ok := intAttr(node.Attrs, "width"); ok {
+		attrs += ` width="` + strconv.Itoa(width) + `"`
+	}
+	if height, ok := intAttr(node.Attrs, "height"); ok {
+		attrs += ` height="` + strconv.Itoa(height) + `"`
+	}
+	w.WriteString("<img" + attrs + ">")
+}
+
+// renderMediaGroup renders a mediaGroup node as a list of links to the
+// files
+func (r *HTMLRenderer) renderMediaGroup(w *markdownWriter, node *Node) {
+	w.ensureNewlines(1)
+	w.WriteString(`<ul class="media-group">`)
+	for i := range node.Content {
+		media := &node.Content[i]
+		if media.Type != "media" {
+			continue
+		}
+		w.ensureNewlines(1)
+		w.state.enter(media)
+		w.WriteString("<li>")
+		r.renderMediaLink(w, media)
+		w.WriteString("</li>")
+		w.state.leave()
+	}
+	w.ensureNewlines(1)
+	w.WriteString("</ul>")
+	w.ensureNewlines(1)
+}
+
+// renderMediaLink renders a media node as a link to the file, labelled
+// with its name
+func (r *HTMLRenderer) renderMediaLink(w *markdownWriter, node *Node) {
+	name, _ := node.Attrs["alt"].(string)
+	if name == "" {
+		name, _ = node.Attrs["id"].(string)
+	}
+	if name == "" {
+		name = "attachment"
+	}
+
+	src, ok := r.options.mediaURL(node)
+	href := htmlHref(src)
+	if !ok || href == "" {
+		w.state.warn(WarningUnresolvedMedia, node.Type+" has no URL or id to link to")
+		w.WriteString(`<span class="media-unresolved">[Attachment: ` + htmlText(name) + "]</span>")
+		return
+	}
+	w.WriteString("<a" + href + ">" + htmlText(name) + "</a>")
+}
+
+// renderCard renders a smart link. Inline cards are links, while block and
+// embed cards are a block with the page's name and summary.
+func (r *HTMLRenderer) renderCard(w *markdownWriter, node *Node) {
+	link, name, summary := cardDetails(node)
+	href := htmlHref(link)
+	if href == "" {
+		w.state.warn(WarningMissingAttr, node.Type+" has no url")
+		return
+	}
+	if name == "" {
+		name = link
+	}
+
+	anchor := "<a" + href + ">" + htmlText(name) + "</a>"
+	if node.Type == "inlineCard" {
+		w.WriteString(anchor)
+		return
+	}
+
+	w.ensureNewlines(1)
+	w.WriteString(`<div class="card">` + anchor)
+	if summary != "" {
+		w.WriteString("<p>" + htmlText(summary) + "</p>")
+	}
+	w.WriteString("</div>")
+	w.ensureNewlines(1)
+}
+
+// renderExpand renders an expand as a collapsible details element
+func (r *HTMLRenderer) renderExpand(w *markdownWriter, node *Node) {
+	w.ensureNewlines(1)
+	w.WriteString(`<details class="expand">`)
+	if title, _ := node.Attrs["title"].(string); title != "" {
+		w.ensureNewlines(1)
+		w.WriteString("<summary>" + htmlText(title) + "</summary>")
+	}
+	r.renderBlocks(w, node.Content)
+	w.ensureNewlines(1)
+	w.WriteString("</details>")
+	w.ensureNewlines(1)
+}
+
+// renderExtension renders the bodies of bodied extensions, and any other
+// extension as a placeholder naming the macro
+func (r *HTMLRenderer) renderExtension(w *markdownWriter, node *Node) {
+	ext := &Extension{Node: node, Inline: node.Type == "inlineExtension"}
+	ext.Type, _ = node.Attrs["extensionType"].(string)
+	ext.Key, _ = node.Attrs["extensionKey"].(string)
+	ext.Parameters, _ = node.Attrs["parameters"].(map[string]any)
+
+	attrs := ` class="extension" data-extension-key="` + html.EscapeString(ext.Key) + `"`
+	switch node.Type {
+	case "bodiedExtension", "multiBodiedExtension":
+		r.renderContainer(w, "div", attrs, node.Content)
+	case "inlineExtension":
+		w.state.warn(WarningLossy, "extension "+ext.Key+" written as a placeholder")
+		w.WriteString("<span" + attrs + ">[Macro: " + htmlText(ext.Title()) + "]</span>")
+	default:
+		w.state.warn(WarningLossy, "extension "+ext.Key+" written as a placeholder")
+		r.renderBlock(w, "<div"+attrs+">[Macro: "+htmlText(ext.Title())+"]</div>")
+	}
+}
+
+// renderTable renders a table, putting a leading row of header cells in a
+// <thead>
+func (r *HTMLRenderer) renderTable(w *markdownWriter, node *Node) {
+	rows := tableRows(node)
+
+	w.ensureNewlines(1)
+	w.WriteString("<table>")
+	if len(rows) > 0 && isHeaderRow(rows[0]) {
+		w.ensureNewlines(1)
+		w.WriteString("<thead>")
+		r.renderNode(w, rows[0])
+		w.WriteString("</thead>")
+		rows = rows[1:]
+	}
+	if len(rows) > 0 {
+		w.ensureNewlines(1)
+		w.WriteString("<tbody>")
+		for _, row := range rows {
+			r.renderNode(w, row)
+		}
+		w.WriteString("</tbody>")
+	}
+	w.ensureNewlines(1)
+	w.WriteString("</table>")
+	w.ensureNewlines(1)
+}
+
+// renderTableCell renders a table cell, keeping its spans and width
+func (r *HTMLRenderer) renderTableCell(w *markdownWriter, node *Node) {
+	tag := "td"
+	if node.Type == "tableHeader" {
+		tag = "th"
+	}
+
+	attrs := htmlCellAttrs(node)
+	if background, _ := node.Attrs["background"].(string); isCSSColor(background) {
+		attrs += ` style="background-color: ` + background + `"`
+	}
+
+	w.ensureNewlines(1)
+	w.WriteString("<" + tag + attrs + ">")
+	r.renderFlow(w, node.Content)
+	w.WriteString("</" + tag + ">")
+	w.ensureNewlines(1)
+}
+
+// htmlTextEscaper escapes the characters that are special in HTML text
+var htmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// htmlText escapes text for use as the content of an HTML element
+func htmlText(text string) string {
+	return htmlTextEscaper.Replace(text)
+}
+
+// htmlHref returns an href attribute for a link, or nothing if the URL is
+// empty or uses a scheme that could run script, such as javascript:
+func htmlHref(link string) string {
+	if link == "" {
+		return ""
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto", "tel", "ftp":
+		return ` href="` + html.EscapeString(link) + `"`
+	}
+	return ""
+}
+
+// cssColorPattern matches hex colors and color names, the forms Atlassian
+// stores colors in
+var cssColorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{3,8}|[a-zA-Z]+)$`)
+
+// isCSSColor reports whether a color is safe to write into a style
+// attribute
+func isCSSColor(color string) bool {
+	return cssColorPattern.MatchString(color)
+}
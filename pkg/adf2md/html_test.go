@@ -0,0 +1,125 @@
+package adf2md_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestRenderHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Escaped text with marks",
+			input:    `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"a < b & "},{"type":"text","text":"c","marks":[{"type":"strong"},{"type":"link","attrs":{"href":"https://example.com/?a=1&b=2"}}]},{"type":"text","text":" x","marks":[{"type":"link","attrs":{"href":"javascript:alert(1)"}}]}]}]}`,
+			expected: "<p>a &lt; b &amp; <a href=\"https://example.com/?a=1&amp;b=2\"><strong>c</strong></a><a> x</a></p>\n",
+		},
+		{
+			name:     "Heading and code block",
+			input:    `{"version":1,"type":"doc","content":[{"type":"heading","attrs":{"level":2},"content":[{"type":"text","text":"Setup"}]},{"type":"codeBlock","attrs":{"language":"go"},"content":[{"type":"text","text":"if a < b {\n}"}]}]}`,
+			expected: "<h2>Setup</h2>\n<pre><code class=\"language-go\">if a &lt; b {\n}</code></pre>\n",
+		},
+		{
+			name:     "Nested lists",
+			input:    `{"version":1,"type":"doc","content":[{"type":"orderedList","attrs":{"order":3},"content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"one"}]},{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"nested"}]}]}]}]},{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"two"}]}]}]}]}`,
+			expected: "<ol start=\"3\">\n<li>\n<p>one</p>\n<ul>\n<li>nested</li>\n</ul>\n</li>\n<li>two</li>\n</ol>\n",
+		},
+		{
+			name:     "Tasks",
+			input:    `{"version":1,"type":"doc","content":[{"type":"taskList","attrs":{"localId":"t"},"content":[{"type":"taskItem","attrs":{"localId":"1","state":"DONE"},"content":[{"type":"text","text":"Done"}]},{"type":"taskItem","attrs":{"localId":"2","state":"TODO"},"content":[{"type":"text","text":"Todo"}]}]}]}`,
+			expected: "<ul class=\"task-list\">\n<li class=\"task-item\"><input type=\"checkbox\" disabled checked> Done</li>\n<li class=\"task-item\"><input type=\"checkbox\" disabled> Todo</li>\n</ul>\n",
+		},
+		{
+			name:     "Table with spans",
+			input:    `{"version":1,"type":"doc","content":[{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"H1"}]}]},{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"H2"}]}]}]},{"type":"tableRow","content":[{"type":"tableCell","attrs":{"colspan":2},"content":[{"type":"paragraph","content":[{"type":"text","text":"Both"}]}]}]}]}]}`,
+			expected: "<table>\n<thead>\n<tr>\n<th>H1</th>\n<th>H2</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td colspan=\"2\">Both</td>\n</tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name:     "Expand",
+			input:    `{"version":1,"type":"doc","content":[{"type":"expand","attrs":{"title":"Details <here>"},"content":[{"type":"paragraph","content":[{"type":"text","text":"Hidden"}]}]}]}`,
+			expected: "<details class=\"expand\">\n<summary>Details &lt;here&gt;</summary>\n<p>Hidden</p>\n</details>\n",
+		},
+		{
+			name:     "Media with caption",
+			input:    `{"version":1,"type":"doc","content":[{"type":"mediaSingle","content":[{"type":"media","attrs":{"type":"external","url":"https://example.com/a.png","alt":"Chart","width":640}},{"type":"caption","content":[{"type":"text","text":"Q3 results"}]}]}]}`,
+			expected: "<figure>\n<img src=\"https://example.com/a.png\" alt=\"Chart\" width=\"640\">\n<figcaption>Q3 results</figcaption>\n</figure>\n",
+		},
+		{
+			name:     "Panels and statuses",
+			input:    `{"version":1,"type":"doc","content":[{"type":"panel","attrs":{"panelType":"warning"},"content":[{"type":"paragraph","content":[{"type":"status","attrs":{"text":"Blocked","color":"red"}}]}]},{"type":"panel","attrs":{"panelType":"custom","panelIconText":"🎉","panelColor":"#eae6ff"},"content":[{"type":"paragraph","content":[{"type":"text","text":"Shipped"}]}]}]}`,
+			expected: "<div class=\"panel panel-warning\">\n<p><span class=\"status status-red\">Blocked</span></p>\n</div>\n<div class=\"panel panel-custom\" style=\"background-color: #eae6ff\">\n<span class=\"panel-icon\">🎉</span>\n<p>Shipped</p>\n</div>\n",
+		},
+		{
+			name:     "Mentions and dates",
+			input:    `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"mention","attrs":{"id":"557058:1","text":"@Jane Doe"}},{"type":"text","text":" on "},{"type":"date","attrs":{"timestamp":"1731542400000"}}]}]}`,
+			expected: "<p><span class=\"mention\" data-account-id=\"557058:1\">@Jane Doe</span> on <time datetime=\"2024-11-14T00:00:00Z\">2024-11-14</time></p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := adf2md.ParseADF(tt.input)
+			if err != nil {
+				t.Fatalf("Failed to parse ADF: %v", err)
+			}
+
+			result, err := adf2md.NewHTMLRenderer().RenderToHTML(node)
+			if err != nil {
+				t.Fatalf("RenderToHTML failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRenderHTMLStandalone(t *testing.T) {
+	node, err := adf2md.ParseADF(`{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"Body"}]}]}`)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	renderer := adf2md.NewHTMLRenderer().WithOptions(adf2md.HTMLOptions{Standalone: true, Title: "Q&A"})
+	result, err := renderer.RenderToHTML(node)
+	if err != nil {
+		t.Fatalf("RenderToHTML failed: %v", err)
+	}
+
+	for _, part := range []string{"<!DOCTYPE html>", "<title>Q&amp;A</title>", "<style>", "<body>\n<p>Body</p>\n</body>\n</html>\n"} {
+		if !strings.Contains(result, part) {
+			t.Errorf("Expected page to contain %q, got:\n%s", part, result)
+		}
+	}
+}
+
+func TestRenderHTMLStrict(t *testing.T) {
+	// Far more output than Render buffers before writing, followed by a
+	// node that can't be rendered
+	doc := &adf2md.Node{Type: "doc", Version: 1}
+	for i := 0; i < 500; i++ {
+		doc.Content = append(doc.Content, adf2md.Node{Type: "paragraph", Content: []adf2md.Node{
+			{Type: "text", Text: "Lorem ipsum dolor sit amet, consectetur adipiscing elit"},
+		}})
+	}
+	doc.Content = append(doc.Content, adf2md.Node{Type: "futureBlock"})
+
+	renderer := adf2md.NewHTMLRenderer().WithOptions(adf2md.HTMLOptions{RenderOptions: adf2md.RenderOptions{Strict: true}})
+
+	var out strings.Builder
+	err := renderer.Render(&out, doc)
+
+	var unsupported *adf2md.UnsupportedError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("Render() error = %v, want *UnsupportedError", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Render() wrote %d bytes before failing, want none", out.Len())
+	}
+}
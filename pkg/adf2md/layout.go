@@ -0,0 +1,168 @@
+package adf2md
+
+import (
+	"strconv"
+	"strings"
+)
+
+// LayoutMode controls how multi-column page layouts (layoutSection nodes)
+// are rendered
+type LayoutMode int
+
+const (
+	// LayoutModeSequential renders the columns one after another,
+	// separated by RenderOptions.LayoutSeparator if it is set
+	LayoutModeSequential LayoutMode = iota
+	// LayoutModeHTML renders the columns side by side in an HTML flex
+	// block, keeping their widths
+	LayoutModeHTML
+	// LayoutModeTable renders the columns as the cells of a single-row
+	// table. This is an HTML table that keeps the column widths, or a pipe
+	// table with flattened columns when TableMode is TableModeGFM.
+	LayoutModeTable
+)
+
+// renderLayoutSection renders a layoutSection node according to the
+// configured LayoutMode
+func (r *Renderer) renderLayoutSection(w *markdownWriter, node *Node) {
+	columns := layoutColumns(node)
+	if len(columns) == 0 {
+		return
+	}
+
+	switch r.options.LayoutMode {
+	case LayoutModeHTML:
+		r.renderHTMLLayout(w, columns)
+	case LayoutModeTable:
+		if r.options.TableMode == TableModeGFM {
+			r.renderPipeLayout(w, columns)
+		} else {
+			r.renderTableLayout(w, columns)
+		}
+	default:
+		r.renderSequentialLayout(w, columns)
+	}
+}
+
+// renderSequentialLayout renders columns one after another
+func (r *Renderer) renderSequentialLayout(w *markdownWriter, columns []*Node) {
+	outer := w.state.cells
+	w.state.cells = cellsDefault
+	defer func() { w.state.cells = outer }()
+
+	for i, column := range columns {
+		if i > 0 && r.options.LayoutSeparator != "" {
+			w.ensureNewlines(2)
+			w.WriteString(r.options.LayoutSeparator)
+		}
+		w.ensureNewlines(2)
+		r.renderNode(w, column)
+	}
+	w.ensureNewlines(2)
+}
+
+// renderHTMLLayout renders columns side by side in a flex container, with
+// each column's width as its flex basis
+func (r *Renderer) renderHTMLLayout(w *markdownWriter, columns []*Node) {
+	outer := w.state.cells
+	w.state.cells = cellsFlex
+	defer func() { w.state.cells = outer }()
+
+	w.WriteString(`<div style="display: flex; gap: 1em">`)
+	for _, column := range columns {
+		w.ensureNewlines(1)
+		r.renderNode(w, column)
+	}
+	w.ensureNewlines(1)
+	w.WriteString("</div>")
+	w.ensureNewlines(2)
+}
+
+// renderTableLayout renders columns as the cells of a single-row HTML table
+func (r *Renderer) renderTableLayout(w *markdownWriter, columns []*Node) {
+	outer := w.state.cells
+	w.state.cells = cellsHTML
+	defer func() { w.state.cells = outer }()
+
+	w.WriteString("<table>")
+	w.ensureNewlines(1)
+	w.WriteString("<tr>")
+	for _, column := range columns {
+		w.ensureNewlines(1)
+		r.renderNode(w, column)
+	}
+	w.ensureNewlines(1)
+	w.WriteString("</tr>")
+	w.ensureNewlines(1)
+	w.WriteString("</table>")
+	w.ensureNewlines(2)
+}
+
+// renderPipeLayout renders columns as a pipe table with an empty header
+// row and a single row of flattened cells. Pipe tables can't hold column
+// widths.
+func (r *Renderer) renderPipeLayout(w *markdownWriter, columns []*Node) {
+	outer := w.state.cells
+	w.state.cells = cellsPipe
+	defer func() { w.state.cells = outer }()
+
+	w.WriteString("|" + strings.Repeat("  |", len(columns)))
+	w.ensureNewlines(1)
+	w.WriteString("|" + strings.Repeat(" --- |", len(columns)))
+	w.ensureNewlines(1)
+
+	var row strings.Builder
+	row.WriteString("|")
+	for _, column := range columns {
+		row.WriteString(" " + r.renderToString(w, column) + " |")
+	}
+	w.WriteString(row.String())
+	w.ensureNewlines(2)
+}
+
+// renderLayoutColumn renders a layoutColumn node in the way the layout
+// around it is rendered
+func (r *Renderer) renderLayoutColumn(w *markdownWriter, node *Node) {
+	switch w.state.cells {
+	case cellsFlex:
+		style := "flex: 1 1 0"
+		if width, ok := columnWidth(node); ok {
+			style = "flex: 1 1 " + width
+		}
+		r.renderHTMLBlock(w, "div", ` style="`+style+`"`, node.Content)
+	case cellsHTML:
+		attrs := ""
+		if width, ok := columnWidth(node); ok {
+			attrs = ` width="` + width + `"`
+		}
+		r.renderHTMLBlock(w, "td", attrs, node.Content)
+	case cellsPipe:
+		if hasBlockContent(node) {
+			w.state.warn(WarningLossy, "block content flattened onto one line of a pipe table")
+		}
+		w.WriteString(r.renderFlattened(w, node))
+	default:
+		r.renderContent(w, node.Content)
+		w.ensureNewlines(2)
+	}
+}
+
+// layoutColumns returns the layoutColumn children of a layoutSection node
+func layoutColumns(node *Node) []*Node {
+	var columns []*Node
+	for i := range node.Content {
+		if node.Content[i].Type == "layoutColumn" {
+			columns = append(columns, &node.Content[i])
+		}
+	}
+	return columns
+}
+
+// columnWidth returns the width of a layout column as a CSS percentage
+func columnWidth(column *Node) (string, bool) {
+	width, ok := column.Attrs["width"].(float64)
+	if !ok || width <= 0 {
+		return "", false
+	}
+	return strconv.FormatFloat(width, 'f', -1, 64) + "%", true
+}
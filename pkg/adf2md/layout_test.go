@@ -0,0 +1,65 @@
+package adf2md_test
+
+import (
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestRenderLayout(t *testing.T) {
+	input := `{"version":1,"type":"doc","content":[{"type":"layoutSection","content":[
+		{"type":"layoutColumn","attrs":{"width":33.33},"content":[{"type":"heading","attrs":{"level":2},"content":[{"type":"text","text":"Left"}]},{"type":"paragraph","content":[{"type":"text","text":"One"}]}]},
+		{"type":"layoutColumn","attrs":{"width":66.67},"content":[{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"Two"}]}]}]}]}
+	]},{"type":"paragraph","content":[{"type":"text","text":"After"}]}]}`
+
+	tests := []struct {
+		name     string
+		options  adf2md.RenderOptions
+		expected string
+	}{
+		{
+			name:     "Sequential",
+			options:  adf2md.RenderOptions{ListIndent: 2},
+			expected: "## Left\n\nOne\n\n* Two\n\nAfter\n\n",
+		},
+		{
+			name:     "Sequential with separator",
+			options:  adf2md.RenderOptions{ListIndent: 2, LayoutSeparator: "---"},
+			expected: "## Left\n\nOne\n\n---\n\n* Two\n\nAfter\n\n",
+		},
+		{
+			name:     "HTML",
+			options:  adf2md.RenderOptions{ListIndent: 2, LayoutMode: adf2md.LayoutModeHTML},
+			expected: "<div style=\"display: flex; gap: 1em\">\n<div style=\"flex: 1 1 33.33%\">\n\n## Left\n\nOne\n\n</div>\n<div style=\"flex: 1 1 66.67%\">\n\n* Two\n\n</div>\n</div>\n\nAfter\n\n",
+		},
+		{
+			name:     "Table",
+			options:  adf2md.RenderOptions{ListIndent: 2, LayoutMode: adf2md.LayoutModeTable},
+			expected: "<table>\n<tr>\n<td width=\"33.33%\">\n\n## Left\n\nOne\n\n</td>\n<td width=\"66.67%\">\n\n* Two\n\n</td>\n</tr>\n</table>\n\nAfter\n\n",
+		},
+		{
+			name:     "Pipe table",
+			options:  adf2md.RenderOptions{ListIndent: 2, LayoutMode: adf2md.LayoutModeTable, TableMode: adf2md.TableModeGFM},
+			expected: "|  |  |\n| --- | --- |\n| ## Left<br>One | * Two |\n\nAfter\n\n",
+		},
+	}
+
+	node, err := adf2md.ParseADF(input)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := adf2md.NewRenderer().WithOptions(tt.options)
+			result, err := renderer.RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
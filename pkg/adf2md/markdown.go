@@ -0,0 +1,1123 @@
+package adf2md
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Block-level Markdown syntax
+var (
+	atxHeading      = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?[ \t]*$`)
+	atxClosing      = regexp.MustCompile(`(?:^|[ \t]+)#+$`)
+	fenceOpen       = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*(.*?)[ \t]*$")
+	thematicBreak   = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	listMarker      = regexp.MustCompile(`^( {0,3})([-+*]|[0-9]{1,9}[.)])([ \t]+|$)`)
+	taskMarker      = regexp.MustCompile(`^\[([ xX])\](?:[ \t]+|$)`)
+	blockquoteStart = regexp.MustCompile(`^ {0,3}> ?`)
+	setextUnderline = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	tableDelimiter  = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+)
+
+// Inline Markdown syntax
+var (
+	autolinkURI   = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^<>\s]*)>`)
+	autolinkEmail = regexp.MustCompile(`^<([A-Za-z0-9.!#$%&'*+/=?^_{|}~-]+@[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?(?:\.[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?)*)>`)
+	bareURL       = regexp.MustCompile(`^(?:https?://|www\.)[^\s<]+`)
+	entity        = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+)
+
+// markdownParser converts CommonMark/GFM into ADF nodes. It keeps counters
+// for the localId attrs that ADF requires on task lists and items.
+type markdownParser struct {
+	taskLists int
+	tasks     int
+}
+
+// ParseMarkdown parses a Markdown string into an ADF document. Numeric
+// attributes are stored as float64 so the tree matches what ParseADF
+// produces from JSON.
+func ParseMarkdown(markdown string) (*Node, error) {
+	if !utf8.ValidString(markdown) {
+		return nil, errors.New("invalid UTF-8 in Markdown input")
+	}
+
+	markdown = strings.ReplaceAll(markdown, "\r\n", "\n")
+	markdown = strings.ReplaceAll(markdown, "\r", "\n")
+
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		lines[i] = expandLeadingTabs(line)
+	}
+
+	p := &markdownParser{}
+	return &Node{
+		Type:    "doc",
+		Version: 1,
+		Content: p.parseBlocks(lines),
+	}, nil
+}
+
+// parseBlocks parses a sequence of lines into block nodes
+func (p *markdownParser) parseBlocks(lines []string) []Node {
+	var blocks []Node
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case isBlank(line):
+			i++
+		case fenceOpen.MatchString(line) && isFence(line):
+			var block Node
+			block, i = p.parseFencedCode(lines, i)
+			blocks = append(blocks, block)
+		case atxHeading.MatchString(line):
+			blocks = append(blocks, p.parseATXHeading(line))
+			i++
+		case thematicBreak.MatchString(line):
+			blocks = append(blocks, Node{Type: "rule"})
+			i++
+		case blockquoteStart.MatchString(line):
+			var block Node
+			block, i = p.parseBlockquote(lines, i)
+			blocks = append(blocks, block)
+		case listMarker.MatchString(line):
+			var block []Node
+			block, i = p.parseList(lines, i)
+			blocks = append(blocks, block...)
+		case indentation(line) >= 4:
+			var block Node
+			block, i = p.parseIndentedCode(lines, i)
+			blocks = append(blocks, block)
+		case i+1 < len(lines) && isTableStart(line, lines[i+1]):
+			var block Node
+			block, i = p.parseTable(lines, i)
+			blocks = append(blocks, block)
+		default:
+			var block []Node
+			block, i = p.parseParagraph(lines, i)
+			blocks = append(blocks, block...)
+		}
+	}
+
+	return blocks
+}
+
+// parseFencedCode parses a fenced code block starting at lines[start]
+func (p *markdownParser) parseFencedCode(lines []string, start int) (Node, int) {
+	m := fenceOpen.FindStringSubmatch(lines[start])
+	indent, fence, info := len(m[1]), m[2], m[3]
+
+	var code []string
+	i := start + 1
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if indentation(lines[i]) < 4 && strings.HasPrefix(trimmed, fence[:1]) &&
+			strings.Trim(trimmed, fence[:1]) == "" && len(trimmed) >= len(fence) {
+			i++
+			break
+		}
+		code = append(code, removeIndent(lines[i], indent))
+	}
+
+	node := Node{Type: "codeBlock"}
+	if fields := strings.Fields(info); len(fields) > 0 {
+		node.Attrs = map[string]any{"language": unescapeMarkdown(fields[0])}
+	}
+	if text := strings.Join(code, "\n"); text != "" {
+		node.Content = []Node{{Type: "text", Text: text}}
+	}
+
+	return node, i
+}
+
+// parseIndentedCode parses an indented code block starting at lines[start]
+func (p *markdownParser) parseIndentedCode(lines []string, start int) (Node, int) {
+	var code []string
+	i := start
+	for ; i < len(lines); i++ {
+		if !isBlank(lines[i]) && indentation(lines[i]) < 4 {
+			break
+		}
+		code = append(code, removeIndent(lines[i], 4))
+	}
+
+	// Trailing blank lines belong to whatever comes next
+	for len(code) > 0 && isBlank(code[len(code)-1]) {
+		code = code[:len(code)-1]
+		i--
+	}
+
+	return Node{
+		Type:    "codeBlock",
+		Content: []Node{{Type: "text", Text: strings.Join(code, "\n")}},
+	}, i
+}
+
+// parseATXHeading parses a "#"-style heading line
+func (p *markdownParser) parseATXHeading(line string) Node {
+	m := atxHeading.FindStringSubmatch(line)
+	content := atxClosing.ReplaceAllString(m[2], "")
+
+	return Node{
+		Type:    "heading",
+		Attrs:   map[string]any{"level": float64(len(m[1]))},
+		Content: p.inlineContent(strings.TrimSpace(content)),
+	}
+}
+
+// parseBlockquote parses a blockquote starting at lines[start], including
+// any lazy continuation lines of its last paragraph
+func (p *markdownParser) parseBlockquote(lines []string, start int) (Node, int) {
+	var inner []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if loc := blockquoteStart.FindStringIndex(line); loc != nil {
+			inner = append(inner, line[loc[1]:])
+			continue
+		}
+		if isBlank(line) || len(inner) == 0 || isBlank(inner[len(inner)-1]) || isBlockStart(line) {
+			break
+		}
+		inner = append(inner, line)
+	}
+
+	return Node{Type: "blockquote", Content: p.parseBlocks(inner)}, i
+}
+
+// listItemLines holds the de-indented lines of one list item
+type listItemLines struct {
+	marker string
+	lines  []string
+}
+
+// parseList parses a bullet, ordered or task list starting at lines[start]
+func (p *markdownParser) parseList(lines []string, start int) ([]Node, int) {
+	first := listMarker.FindStringSubmatch(lines[start])
+	ordered := !strings.ContainsAny(first[2], "-+*")
+	delimiter := first[2][len(first[2])-1:]
+
+	var items []listItemLines
+	i := start
+	for i < len(lines) {
+		m := listMarker.FindStringSubmatch(lines[i])
+		if m == nil || thematicBreak.MatchString(lines[i]) || !strings.HasSuffix(m[2], delimiter) {
+			break
+		}
+
+		// Content starts after the marker and up to four spaces of padding.
+		// More padding than that means the content is an indented code block.
+		indent, marker, padding := len(m[1]), m[2], len(m[3])
+		rest := lines[i][len(m[0]):]
+		contentIndent := indent + len(marker) + padding
+		if rest == "" || padding > 4 {
+			contentIndent = indent + len(marker) + 1
+			rest = removeIndent(lines[i][indent+len(marker):], 1)
+		}
+
+		item := listItemLines{marker: marker, lines: []string{rest}}
+		i++
+		for ; i < len(lines); i++ {
+			line := lines[i]
+			if isBlank(line) {
+				item.lines = append(item.lines, "")
+				continue
+			}
+			if indentation(line) >= contentIndent {
+				item.lines = append(item.lines, line[contentIndent:])
+				continue
+			}
+			// Lazy continuation of a paragraph inside the item
+			previous := item.lines[len(item.lines)-1]
+			if !isBlank(previous) && !isBlockStart(line) && !listMarker.MatchString(line) {
+				item.lines = append(item.lines, strings.TrimLeft(line, " "))
+				continue
+			}
+			break
+		}
+
+		// Blank lines at the end of an item only separate it from what follows
+		for len(item.lines) > 1 && isBlank(item.lines[len(item.lines)-1]) {
+			item.lines = item.lines[:len(item.lines)-1]
+		}
+		items = append(items, item)
+
+		// A blank line followed by something other than another item ends the list
+		if i < len(lines) && isBlank(lines[i-1]) && !listMarker.MatchString(lines[i]) {
+			break
+		}
+	}
+
+	if ordered {
+		return []Node{p.buildOrderedList(items)}, i
+	}
+	return p.buildBulletLists(items), i
+}
+
+// buildOrderedList builds an orderedList node from parsed items
+func (p *markdownParser) buildOrderedList(items []listItemLines) Node {
+	list := Node{Type: "orderedList"}
+
+	var start int
+	fmt.Sscanf(items[0].marker, "%d", &start)
+	if start != 1 {
+		list.Attrs = map[string]any{"order": float64(start)}
+	}
+
+	for _, item := range items {
+		list.Content = append(list.Content, p.buildListItem(item.lines))
+	}
+	return list
+}
+
+// buildBulletLists builds bulletList and taskList nodes from parsed items.
+// Consecutive items starting with "[ ]" or "[x]" form a task list, since ADF
+// doesn't allow tasks and plain items in the same list.
+func (p *markdownParser) buildBulletLists(items []listItemLines) []Node {
+	var lists []Node
+	for _, item := range items {
+		isTask := taskMarker.MatchString(item.lines[0])
+		listType := "bulletList"
+		if isTask {
+			listType = "taskList"
+		}
+
+		if len(lists) == 0 || lists[len(lists)-1].Type != listType {
+			list := Node{Type: listType}
+			if isTask {
+				p.taskLists++
+				list.Attrs = map[string]any{"localId": fmt.Sprintf("task-list-%d", p.taskLists)}
+			}
+			lists = append(lists, list)
+		}
+
+		list := &lists[len(lists)-1]
+		if isTask {
+			list.Content = append(list.Content, p.buildTaskItems(item.lines)...)
+		} else {
+			list.Content = append(list.Content, p.buildListItem(item.lines))
+		}
+	}
+	return lists
+}
+
+// buildListItem builds a listItem node from an item's lines
+func (p *markdownParser) buildListItem(lines []string) Node {
+	content := p.parseBlocks(lines)
+	if len(content) == 0 {
+		content = []Node{{Type: "paragraph"}}
+	}
+	return Node{Type: "listItem", Content: content}
+}
+
+// buildTaskItems builds a taskItem node from an item's lines. A taskItem only
+// holds inline content, so nested task lists become siblings of the item and
+// any other blocks are flattened onto new lines within it.
+func (p *markdownParser) buildTaskItems(lines []string) []Node {
+	m := taskMarker.FindStringSubmatch(lines[0])
+	state := "TODO"
+	if m[1] != " " {
+		state = "DONE"
+	}
+
+	lines = append([]string{lines[0][len(m[0]):]}, lines[1:]...)
+
+	p.tasks++
+	item := Node{
+		Type:  "taskItem",
+		Attrs: map[string]any{"localId": fmt.Sprintf("task-%d", p.tasks), "state": state},
+	}
+
+	var nested []Node
+	for _, block := range p.parseBlocks(lines) {
+		if block.Type == "taskList" {
+			nested = append(nested, block)
+			continue
+		}
+		if len(item.Content) > 0 {
+			item.Content = append(item.Content, Node{Type: "hardBreak"})
+		}
+		item.Content = append(item.Content, flattenInline(&block)...)
+	}
+
+	return append([]Node{item}, nested...)
+}
+
+// parseTable parses a GFM table whose header row is lines[start]
+func (p *markdownParser) parseTable(lines []string, start int) (Node, int) {
+	header := splitTableRow(lines[start])
+	delimiters := splitTableRow(lines[start+1])
+
+	alignments := make([]string, len(delimiters))
+	for i, d := range delimiters {
+		switch {
+		case strings.HasPrefix(d, ":") && strings.HasSuffix(d, ":"):
+			alignments[i] = "center"
+		case strings.HasSuffix(d, ":"):
+			alignments[i] = "end"
+		}
+	}
+
+	table := Node{Type: "table"}
+	table.Content = append(table.Content, p.buildTableRow(header, alignments, "tableHeader"))
+
+	i := start + 2
+	for ; i < len(lines); i++ {
+		if isBlank(lines[i]) || isBlockStart(lines[i]) {
+			break
+		}
+		table.Content = append(table.Content, p.buildTableRow(splitTableRow(lines[i]), alignments, "tableCell"))
+	}
+
+	return table, i
+}
+
+// buildTableRow builds a tableRow with one cell per column
+func (p *markdownParser) buildTableRow(cells []string, alignments []string, cellType string) Node {
+	row := Node{Type: "tableRow"}
+	for i, align := range alignments {
+		paragraph := Node{Type: "paragraph"}
+		if i < len(cells) {
+			paragraph.Content = p.inlineContent(cells[i])
+		}
+		if align != "" {
+			paragraph.Marks = []Mark{{Type: "alignment", Attrs: map[string]any{"align": align}}}
+		}
+		row.Content = append(row.Content, Node{Type: cellType, Content: []Node{paragraph}})
+	}
+	return row
+}
+
+// parseParagraph parses a paragraph starting at lines[start]. It returns a
+// heading instead if the paragraph is underlined setext-style, and
+// mediaSingle nodes if the paragraph holds nothing but images.
+func (p *markdownParser) parseParagraph(lines []string, start int) ([]Node, int) {
+	var text []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if i > start {
+			if m := setextUnderline.FindStringSubmatch(line); m != nil {
+				level := 2
+				if strings.HasPrefix(m[1], "=") {
+					level = 1
+				}
+				return []Node{{
+					Type:    "heading",
+					Attrs:   map[string]any{"level": float64(level)},
+					Content: p.inlineContent(strings.Join(text, "\n")),
+				}}, i + 1
+			}
+			if isBlank(line) || isBlockStart(line) || (i+1 < len(lines) && isTableStart(line, lines[i+1])) {
+				break
+			}
+		}
+		text = append(text, strings.TrimLeft(line, " "))
+	}
+
+	inlines := p.parseInlines(strings.TrimRight(strings.Join(text, "\n"), " \t"), nil)
+
+	if images := imagesOnly(inlines); images != nil {
+		return images, i
+	}
+	return []Node{{Type: "paragraph", Content: imagesToLinks(inlines)}}, i
+}
+
+// inlineContent parses inline Markdown for a node that can't hold images
+func (p *markdownParser) inlineContent(text string) []Node {
+	return imagesToLinks(p.parseInlines(strings.TrimSpace(text), nil))
+}
+
+// parseInlines parses inline Markdown into text and hardBreak nodes, adding
+// marks to the given outer marks as emphasis, links and code are entered.
+// Images are returned as media nodes for the caller to place.
+func (p *markdownParser) parseInlines(text string, marks []Mark) []Node {
+	src := []rune(text)
+
+	var nodes []Node
+	var buf strings.Builder
+	flush := func() {
+		if buf.Len() > 0 {
+			nodes = append(nodes, textNode(buf.String(), marks))
+			buf.Reset()
+		}
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\\' && i+1 < len(src) && src[i+1] == '\n':
+			flush()
+			nodes = append(nodes, Node{Type: "hardBreak"})
+			i = skipSpaces(src, i+2)
+
+		case c == '\\' && i+1 < len(src) && isASCIIPunct(src[i+1]):
+			buf.WriteRune(src[i+1])
+			i += 2
+
+		case c == '`':
+			n := runLength(src, i)
+			end := findCodeSpanEnd(src, i+n, n)
+			if end < 0 {
+				buf.WriteString(string(src[i : i+n]))
+				i += n
+				continue
+			}
+			flush()
+			nodes = append(nodes, textNode(normalizeCodeSpan(string(src[i+n:end])), codeMarks(marks)))
+			i = end + n
+
+		case c == '*' || c == '_' || c == '~':
+			n := runLength(src, i)
+			emphasis := delimiterMarks(c, n)
+			end := -1
+			if emphasis != nil {
+				end = findEmphasisEnd(src, i, n)
+			}
+			if end < 0 {
+				buf.WriteString(string(src[i : i+n]))
+				i += n
+				continue
+			}
+			flush()
+			nodes = append(nodes, p.parseInlines(string(src[i+n:end]), appendMarks(marks, emphasis...))...)
+			i = end + n
+
+		case c == '!' && i+1 < len(src) && src[i+1] == '[':
+			label, href, _, end, ok := parseLink(src, i+1)
+			if !ok {
+				buf.WriteRune(c)
+				i++
+				continue
+			}
+			flush()
+			nodes = append(nodes, Node{
+				Type:  "media",
+				Attrs: map[string]any{"type": "external", "url": href, "alt": plainText(label)},
+				Marks: linkMarks(marks),
+			})
+			i = end
+
+		case c == '[':
+			label, href, title, end, ok := parseLink(src, i)
+			if !ok || hasLink(marks) {
+				buf.WriteRune(c)
+				i++
+				continue
+			}
+			flush()
+			nodes = append(nodes, p.parseInlines(label, appendMarks(marks, newLinkMark(href, title)))...)
+			i = end
+
+		case c == '<':
+			rest := string(src[i:])
+			if m := autolinkURI.FindStringSubmatch(rest); m != nil {
+				flush()
+				nodes = append(nodes, textNode(m[1], appendMarks(marks, newLinkMark(m[1], ""))))
+				i += utf8.RuneCountInString(m[0])
+				continue
+			}
+			if m := autolinkEmail.FindStringSubmatch(rest); m != nil {
+				flush()
+				nodes = append(nodes, textNode(m[1], appendMarks(marks, newLinkMark("mailto:"+m[1], ""))))
+				i += utf8.RuneCountInString(m[0])
+				continue
+			}
+			buf.WriteRune(c)
+			i++
+
+		case c == '&':
+			if m := entity.FindString(string(src[i:])); m != "" {
+				buf.WriteString(html.UnescapeString(m))
+				i += utf8.RuneCountInString(m)
+				continue
+			}
+			buf.WriteRune(c)
+			i++
+
+		case c == '\n':
+			// Two or more trailing spaces make a hard break, otherwise
+			// the line break is soft and becomes a space
+			line := buf.String()
+			trimmed := strings.TrimRight(line, " ")
+			buf.Reset()
+			buf.WriteString(trimmed)
+			if len(line)-len(trimmed) >= 2 {
+				flush()
+				nodes = append(nodes, Node{Type: "hardBreak"})
+			} else {
+				buf.WriteRune(' ')
+			}
+			i = skipSpaces(src, i+1)
+
+		case (c == 'h' || c == 'w') && !hasLink(marks) && (i == 0 || isURLBoundary(src[i-1])):
+			url := trimURL(bareURL.FindString(string(src[i:])))
+			if url == "" {
+				buf.WriteRune(c)
+				i++
+				continue
+			}
+			href := url
+			if strings.HasPrefix(url, "www.") {
+				href = "http://" + url
+			}
+			flush()
+			nodes = append(nodes, textNode(url, appendMarks(marks, newLinkMark(href, ""))))
+			i += utf8.RuneCountInString(url)
+
+		default:
+			buf.WriteRune(c)
+			i++
+		}
+	}
+
+	flush()
+	return mergeTextNodes(nodes)
+}
+
+// parseLink parses a "[label](destination "title")" inline link whose
+// opening bracket is src[start]. It returns the end offset past the ")".
+func parseLink(src []rune, start int) (label, href, title string, end int, ok bool) {
+	// Find the matching close bracket
+	depth := 0
+	close := -1
+	for i := start; i < len(src) && close < 0; i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '`':
+			n := runLength(src, i)
+			if e := findCodeSpanEnd(src, i+n, n); e >= 0 {
+				i = e + n - 1
+			} else {
+				i += n - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				close = i
+			}
+		}
+	}
+	if close < 0 || close+1 >= len(src) || src[close+1] != '(' {
+		return "", "", "", 0, false
+	}
+
+	i := skipWhitespace(src, close+2)
+
+	// Destination, either <bracketed> or with balanced parentheses
+	var dest strings.Builder
+	if i < len(src) && src[i] == '<' {
+		i++
+		for ; i < len(src) && src[i] != '>'; i++ {
+			if src[i] == '\n' || src[i] == '<' {
+				return "", "", "", 0, false
+			}
+			dest.WriteRune(src[i])
+		}
+		if i >= len(src) {
+			return "", "", "", 0, false
+		}
+		i++
+	} else {
+		parens := 0
+		for ; i < len(src); i++ {
+			c := src[i]
+			if unicode.IsSpace(c) || (c == ')' && parens == 0) {
+				break
+			}
+			if c == '\\' && i+1 < len(src) && isASCIIPunct(src[i+1]) {
+				dest.WriteRune(c)
+				i++
+				c = src[i]
+			} else if c == '(' {
+				parens++
+			} else if c == ')' {
+				parens--
+			}
+			dest.WriteRune(c)
+		}
+	}
+
+	// Optional title
+	j := skipWhitespace(src, i)
+	if j > i && j < len(src) && strings.ContainsRune(`"'(`, src[j]) {
+		closer := src[j]
+		if closer == '(' {
+			closer = ')'
+		}
+		k := j + 1
+		for ; k < len(src) && src[k] != closer; k++ {
+			if src[k] == '\\' {
+				k++
+			}
+		}
+		if k >= len(src) {
+			return "", "", "", 0, false
+		}
+		title = unescapeMarkdown(string(src[j+1 : k]))
+		j = skipWhitespace(src, k+1)
+	}
+
+	if j >= len(src) || src[j] != ')' {
+		return "", "", "", 0, false
+	}
+
+	return string(src[start+1 : close]), unescapeMarkdown(dest.String()), title, j + 1, true
+}
+
+// findCodeSpanEnd returns the offset of a backtick run of length n closing
+// a code span that starts at from, or -1 if there isn't one
+func findCodeSpanEnd(src []rune, from, n int) int {
+	for i := from; i < len(src); {
+		if src[i] != '`' {
+			i++
+			continue
+		}
+		m := runLength(src, i)
+		if m == n {
+			return i
+		}
+		i += m
+	}
+	return -1
+}
+
+// findEmphasisEnd returns the offset of the delimiter run closing the
+// emphasis opened by the run of n delimiters at src[start], or -1. Closers
+// must have the same length as the opener, which handles the common nesting
+// cases without the full CommonMark delimiter algorithm.
+func findEmphasisEnd(src []rune, start, n int) int {
+	c := src[start]
+	if !leftFlanking(src, start, n) || (c == '_' && start > 0 && isWordRune(src[start-1])) {
+		return -1
+	}
+
+	for i := start + n; i < len(src); {
+		switch src[i] {
+		case '\\':
+			i += 2
+			continue
+		case '`':
+			m := runLength(src, i)
+			if e := findCodeSpanEnd(src, i+m, m); e >= 0 {
+				i = e + m
+			} else {
+				i += m
+			}
+			continue
+		case c:
+			m := runLength(src, i)
+			if m == n && i > start+n && rightFlanking(src, i, m) &&
+				(c != '_' || i+m >= len(src) || !isWordRune(src[i+m])) {
+				return i
+			}
+			i += m
+			continue
+		}
+		i++
+	}
+	return -1
+}
+
+// delimiterMarks returns the marks for a run of n emphasis delimiters, or
+// nil if the run doesn't form emphasis
+func delimiterMarks(c rune, n int) []Mark {
+	if c == '~' {
+		if n > 2 {
+			return nil
+		}
+		return []Mark{{Type: "strike"}}
+	}
+
+	switch n {
+	case 1:
+		return []Mark{{Type: "em"}}
+	case 2:
+		return []Mark{{Type: "strong"}}
+	case 3:
+		return []Mark{{Type: "strong"}, {Type: "em"}}
+	}
+	return nil
+}
+
+// leftFlanking reports whether a delimiter run can open emphasis
+func leftFlanking(src []rune, start, n int) bool {
+	if start+n >= len(src) || unicode.IsSpace(src[start+n]) {
+		return false
+	}
+	next := src[start+n]
+	return !isPunct(next) || start == 0 || unicode.IsSpace(src[start-1]) || isPunct(src[start-1])
+}
+
+// rightFlanking reports whether a delimiter run can close emphasis
+func rightFlanking(src []rune, start, n int) bool {
+	if start == 0 || unicode.IsSpace(src[start-1]) {
+		return false
+	}
+	prev := src[start-1]
+	return !isPunct(prev) || start+n >= len(src) || unicode.IsSpace(src[start+n]) || isPunct(src[start+n])
+}
+
+// imagesOnly returns mediaSingle nodes if the inline nodes hold nothing but
+// images and whitespace, or nil otherwise
+func imagesOnly(nodes []Node) []Node {
+	var media []Node
+	for _, node := range nodes {
+		switch {
+		case node.Type == "media":
+			media = append(media, Node{
+				Type:    "mediaSingle",
+				Attrs:   map[string]any{"layout": "center"},
+				Content: []Node{{Type: "media", Attrs: node.Attrs}},
+			})
+		case node.Type == "text" && strings.TrimSpace(node.Text) == "":
+		default:
+			return nil
+		}
+	}
+	return media
+}
+
+// imagesToLinks replaces inline images, which ADF can't place inside text,
+// with links to the image
+func imagesToLinks(nodes []Node) []Node {
+	for i, node := range nodes {
+		if node.Type != "media" {
+			continue
+		}
+		url, _ := node.Attrs["url"].(string)
+		text, _ := node.Attrs["alt"].(string)
+		if text == "" {
+			text = url
+		}
+		nodes[i] = textNode(text, appendMarks(node.Marks, newLinkMark(url, "")))
+	}
+	return mergeTextNodes(nodes)
+}
+
+// flattenInline returns the inline content of a block node, joining nested
+// blocks with hard breaks
+func flattenInline(node *Node) []Node {
+	switch node.Type {
+	case "text", "hardBreak":
+		return []Node{*node}
+	case "mediaSingle":
+		return imagesToLinks([]Node{node.Content[0]})
+	case "codeBlock":
+		if len(node.Content) > 0 {
+			return []Node{textNode(node.Content[0].Text, []Mark{{Type: "code"}})}
+		}
+		return nil
+	}
+
+	var result []Node
+	for _, child := range node.Content {
+		inline := flattenInline(&child)
+		if len(inline) == 0 {
+			continue
+		}
+		if len(result) > 0 && !isInlineNode(&child) {
+			result = append(result, Node{Type: "hardBreak"})
+		}
+		result = append(result, inline...)
+	}
+	return result
+}
+
+// mergeTextNodes joins adjacent text nodes that carry the same marks
+func mergeTextNodes(nodes []Node) []Node {
+	var result []Node
+	for _, node := range nodes {
+		if node.Type == "text" && node.Text == "" {
+			continue
+		}
+		if n := len(result); n > 0 && node.Type == "text" && result[n-1].Type == "text" &&
+			sameMarks(result[n-1].Marks, node.Marks) {
+			result[n-1].Text += node.Text
+			continue
+		}
+		result = append(result, node)
+	}
+	return result
+}
+
+// textNode creates a text node with its own copy of the marks
+func textNode(text string, marks []Mark) Node {
+	node := Node{Type: "text", Text: text}
+	if len(marks) > 0 {
+		node.Marks = append([]Mark(nil), marks...)
+	}
+	return node
+}
+
+// appendMarks returns a copy of marks with extra appended, leaving the
+// original slice untouched for sibling nodes
+func appendMarks(marks []Mark, extra ...Mark) []Mark {
+	result := make([]Mark, 0, len(marks)+len(extra))
+	result = append(result, marks...)
+	for _, mark := range extra {
+		if !containsMark(result, mark.Type) {
+			result = append(result, mark)
+		}
+	}
+	return result
+}
+
+// codeMarks returns the marks allowed on code text. ADF only lets the code
+// mark combine with links.
+func codeMarks(marks []Mark) []Mark {
+	return append(linkMarks(marks), Mark{Type: "code"})
+}
+
+// linkMarks returns only the link marks from marks
+func linkMarks(marks []Mark) []Mark {
+	var result []Mark
+	for _, mark := range marks {
+		if mark.Type == "link" {
+			result = append(result, mark)
+		}
+	}
+	return result
+}
+
+// newLinkMark creates a link mark, with a title attr if one was given
+func newLinkMark(href, title string) Mark {
+	attrs := map[string]any{"href": href}
+	if title != "" {
+		attrs["title"] = title
+	}
+	return Mark{Type: "link", Attrs: attrs}
+}
+
+// hasLink reports whether marks include a link, since links can't nest
+func hasLink(marks []Mark) bool {
+	return containsMark(marks, "link")
+}
+
+// containsMark reports whether marks include a mark of the given type
+func containsMark(marks []Mark, markType string) bool {
+	for _, mark := range marks {
+		if mark.Type == markType {
+			return true
+		}
+	}
+	return false
+}
+
+// sameMarks reports whether two mark lists are identical
+func sameMarks(a, b []Mark) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type || fmt.Sprint(a[i].Attrs) != fmt.Sprint(b[i].Attrs) {
+			return false
+		}
+	}
+	return true
+}
+
+// plainText returns inline Markdown with its syntax removed, for image alt text
+func plainText(markdown string) string {
+	var text strings.Builder
+	for _, node := range (&markdownParser{}).parseInlines(markdown, nil) {
+		if node.Type == "text" {
+			text.WriteString(node.Text)
+		} else if node.Type == "media" {
+			alt, _ := node.Attrs["alt"].(string)
+			text.WriteString(alt)
+		}
+	}
+	return text.String()
+}
+
+// normalizeCodeSpan converts line endings in a code span to spaces and
+// strips one space of padding from each side
+func normalizeCodeSpan(code string) string {
+	code = strings.ReplaceAll(code, "\n", " ")
+	if len(code) > 2 && strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ") &&
+		strings.Trim(code, " ") != "" {
+		code = code[1 : len(code)-1]
+	}
+	return code
+}
+
+// unescapeMarkdown resolves backslash escapes and entities in a link
+// destination, title or code info string
+func unescapeMarkdown(text string) string {
+	var result strings.Builder
+	src := []rune(text)
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\\' && i+1 < len(src) && isASCIIPunct(src[i+1]) {
+			i++
+		}
+		result.WriteRune(src[i])
+	}
+	return html.UnescapeString(result.String())
+}
+
+// trimURL removes trailing punctuation that GFM doesn't treat as part of a
+// bare URL, including a closing parenthesis with no matching opener
+func trimURL(url string) string {
+	for url != "" {
+		last := url[len(url)-1]
+		switch {
+		case strings.IndexByte("?!.,:*_~'\"", last) >= 0:
+			url = url[:len(url)-1]
+		case last == ')' && strings.Count(url, ")") > strings.Count(url, "("):
+			url = url[:len(url)-1]
+		default:
+			return url
+		}
+	}
+	return url
+}
+
+// isURLBoundary reports whether a bare URL may start after the rune c
+func isURLBoundary(c rune) bool {
+	return unicode.IsSpace(c) || strings.ContainsRune("*_~(", c)
+}
+
+// isBlockStart reports whether a line starts a block that interrupts a
+// paragraph. Ordered lists only interrupt a paragraph when starting at 1.
+func isBlockStart(line string) bool {
+	if atxHeading.MatchString(line) || thematicBreak.MatchString(line) ||
+		blockquoteStart.MatchString(line) || (fenceOpen.MatchString(line) && isFence(line)) {
+		return true
+	}
+	if m := listMarker.FindStringSubmatch(line); m != nil && strings.TrimSpace(line[len(m[0]):]) != "" {
+		return strings.ContainsAny(m[2], "-+*") || m[2][:len(m[2])-1] == "1"
+	}
+	return false
+}
+
+// isFence reports whether a fence-like line really opens a code block.
+// Backtick fences can't have backticks in their info string.
+func isFence(line string) bool {
+	m := fenceOpen.FindStringSubmatch(line)
+	return m[2][0] != '`' || !strings.Contains(m[3], "`")
+}
+
+// isTableStart reports whether line is a table header followed by a
+// delimiter row with the same number of columns
+func isTableStart(line, next string) bool {
+	if !strings.Contains(line, "|") || !tableDelimiter.MatchString(next) {
+		return false
+	}
+	return len(splitTableRow(line)) == len(splitTableRow(next))
+}
+
+// splitTableRow splits a table row into trimmed cells on unescaped pipes.
+// Escaped pipes are unescaped, including inside code spans.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// isInlineNode reports whether a node is inline content
+func isInlineNode(node *Node) bool {
+	return node.Type == "text" || node.Type == "hardBreak"
+}
+
+// runLength returns the length of the run of identical runes at src[start]
+func runLength(src []rune, start int) int {
+	n := 1
+	for start+n < len(src) && src[start+n] == src[start] {
+		n++
+	}
+	return n
+}
+
+// skipSpaces returns the offset of the first non-space rune at or after i
+func skipSpaces(src []rune, i int) int {
+	for i < len(src) && src[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+// skipWhitespace returns the offset of the first non-whitespace rune at or after i
+func skipWhitespace(src []rune, i int) int {
+	for i < len(src) && unicode.IsSpace(src[i]) {
+		i++
+	}
+	return i
+}
+
+// isASCIIPunct reports whether c is ASCII punctuation, which is what a
+// backslash can escape
+func isASCIIPunct(c rune) bool {
+	return c < utf8.RuneSelf && unicode.IsPunct(c) || strings.ContainsRune("$+<=>^`|~", c)
+}
+
+// isPunct reports whether c counts as punctuation for emphasis flanking rules
+func isPunct(c rune) bool {
+	return unicode.IsPunct(c) || unicode.IsSymbol(c)
+}
+
+// isBlank reports whether a line holds only whitespace
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// indentation returns the number of leading spaces on a line
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// removeIndent removes up to n leading spaces from a line
+func removeIndent(line string, n int) string {
+	for i := 0; i < n && strings.HasPrefix(line, " "); i++ {
+		line = line[1:]
+	}
+	return line
+}
+
+// expandLeadingTabs replaces tabs in a line's indentation with spaces up
+// to the next multiple of four columns
+func expandLeadingTabs(line string) string {
+	var result strings.Builder
+	column := 0
+	for i, c := range line {
+		switch c {
+		case ' ':
+			result.WriteByte(' ')
+			column++
+		case '\t':
+			spaces := 4 - column%4
+			result.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+		default:
+			return result.String() + line[i:]
+		}
+	}
+	return result.String()
+}
@@ -0,0 +1,130 @@
+package adf2md_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Empty document",
+			input:    "",
+			expected: `{"type":"doc","version":1}`,
+		},
+		{
+			name:     "Headings",
+			input:    "# One #\n\nTwo\n---",
+			expected: `{"type":"doc","version":1,"content":[{"type":"heading","content":[{"type":"text","text":"One"}],"attrs":{"level":1}},{"type":"heading","content":[{"type":"text","text":"Two"}],"attrs":{"level":2}}]}`,
+		},
+		{
+			name:     "Emphasis",
+			input:    "*em* **strong** ***both*** ~~strike~~ snake_case_name 2 * 3",
+			expected: `{"type":"doc","version":1,"content":[{"type":"paragraph","content":[{"type":"text","text":"em","marks":[{"type":"em"}]},{"type":"text","text":" "},{"type":"text","text":"strong","marks":[{"type":"strong"}]},{"type":"text","text":" "},{"type":"text","text":"both","marks":[{"type":"strong"},{"type":"em"}]},{"type":"text","text":" "},{"type":"text","text":"strike","marks":[{"type":"strike"}]},{"type":"text","text":" snake_case_name 2 * 3"}]}]}`,
+		},
+		{
+			name:     "Nested emphasis",
+			input:    "**bold *both* bold**",
+			expected: `{"type":"doc","version":1,"content":[{"type":"paragraph","content":[{"type":"text","text":"bold ","marks":[{"type":"strong"}]},{"type":"text","text":"both","marks":[{"type":"strong"},{"type":"em"}]},{"type":"text","text":" bold","marks":[{"type":"strong"}]}]}]}`,
+		},
+		{
+			name:     "Code spans only keep link marks",
+			input:    "**[`code`](https://example.com)**",
+			expected: `{"type":"doc","version":1,"content":[{"type":"paragraph","content":[{"type":"text","text":"code","marks":[{"type":"link","attrs":{"href":"https://example.com"}},{"type":"code"}]}]}]}`,
+		},
+		{
+			name:     "Links and autolinks",
+			input:    "[docs](https://example.com \"Docs\") <https://a.example> see www.example.com.",
+			expected: `{"type":"doc","version":1,"content":[{"type":"paragraph","content":[{"type":"text","text":"docs","marks":[{"type":"link","attrs":{"href":"https://example.com","title":"Docs"}}]},{"type":"text","text":" "},{"type":"text","text":"https://a.example","marks":[{"type":"link","attrs":{"href":"https://a.example"}}]},{"type":"text","text":" see "},{"type":"text","text":"www.example.com","marks":[{"type":"link","attrs":{"href":"http://www.example.com"}}]},{"type":"text","text":"."}]}]}`,
+		},
+		{
+			name:     "Escapes, entities and hard breaks",
+			input:    "\\*not em\\* &amp;  \nnext\\\nlast\nsoft",
+			expected: `{"type":"doc","version":1,"content":[{"type":"paragraph","content":[{"type":"text","text":"*not em* \u0026"},{"type":"hardBreak"},{"type":"text","text":"next"},{"type":"hardBreak"},{"type":"text","text":"last soft"}]}]}`,
+		},
+		{
+			name:     "Nested lists",
+			input:    "* a\n* b\n  1. c\n  2. d\n\n3. e",
+			expected: `{"type":"doc","version":1,"content":[{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"a"}]}]},{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"b"}]},{"type":"orderedList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"c"}]}]},{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"d"}]}]}]}]}]},{"type":"orderedList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"e"}]}]}],"attrs":{"order":3}}]}`,
+		},
+		{
+			name:     "Task lists",
+			input:    "- [ ] todo\n- [x] done\n  - [ ] sub",
+			expected: `{"type":"doc","version":1,"content":[{"type":"taskList","content":[{"type":"taskItem","content":[{"type":"text","text":"todo"}],"attrs":{"localId":"task-1","state":"TODO"}},{"type":"taskItem","content":[{"type":"text","text":"done"}],"attrs":{"localId":"task-2","state":"DONE"}},{"type":"taskList","content":[{"type":"taskItem","content":[{"type":"text","text":"sub"}],"attrs":{"localId":"task-3","state":"TODO"}}],"attrs":{"localId":"task-list-2"}}],"attrs":{"localId":"task-list-1"}}]}`,
+		},
+		{
+			name:     "Fenced and indented code",
+			input:    "```go\nfunc main() {}\n```\n\n    plain code",
+			expected: `{"type":"doc","version":1,"content":[{"type":"codeBlock","content":[{"type":"text","text":"func main() {}"}],"attrs":{"language":"go"}},{"type":"codeBlock","content":[{"type":"text","text":"plain code"}]}]}`,
+		},
+		{
+			name:     "Blockquote with lazy continuation",
+			input:    "> quoted\ncontinued\n\n---",
+			expected: `{"type":"doc","version":1,"content":[{"type":"blockquote","content":[{"type":"paragraph","content":[{"type":"text","text":"quoted continued"}]}]},{"type":"rule"}]}`,
+		},
+		{
+			name:     "Table",
+			input:    "| A | B |\n|---|--:|\n| `a\\|b` | 2 |",
+			expected: `{"type":"doc","version":1,"content":[{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"A"}]}]},{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"B"}],"marks":[{"type":"alignment","attrs":{"align":"end"}}]}]}]},{"type":"tableRow","content":[{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"a|b","marks":[{"type":"code"}]}]}]},{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"2"}],"marks":[{"type":"alignment","attrs":{"align":"end"}}]}]}]}]}]}`,
+		},
+		{
+			name:     "Images",
+			input:    "![diagram](https://example.com/d.png)\n\nsee ![inline](https://example.com/i.png)",
+			expected: `{"type":"doc","version":1,"content":[{"type":"mediaSingle","content":[{"type":"media","attrs":{"alt":"diagram","type":"external","url":"https://example.com/d.png"}}],"attrs":{"layout":"center"}},{"type":"paragraph","content":[{"type":"text","text":"see "},{"type":"text","text":"inline","marks":[{"type":"link","attrs":{"href":"https://example.com/i.png"}}]}]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := adf2md.ParseMarkdown(tt.input)
+			if err != nil {
+				t.Fatalf("ParseMarkdown failed: %v", err)
+			}
+
+			result, err := json.Marshal(node)
+			if err != nil {
+				t.Fatalf("Failed to encode ADF: %v", err)
+			}
+
+			if string(result) != tt.expected {
+				t.Errorf("\nExpected: %s\nGot:      %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	tests := []string{
+		"## Heading\n\n",
+		"Plain **bold** *italic* `code` ~~strike~~ [link](https://example.com)\n\n",
+		"* Item 1\n* Item 2\n",
+		"1. First\n2. Second\n",
+		"- [ ] Todo\n- [x] Done\n",
+		"```go\nfunc main() {}\n```\n\n",
+		"| A | B |\n| --- | --- |\n| 1 | 2 |\n\n",
+	}
+
+	renderer := adf2md.NewRenderer()
+
+	for _, markdown := range tests {
+		node, err := adf2md.ParseMarkdown(markdown)
+		if err != nil {
+			t.Fatalf("ParseMarkdown failed: %v", err)
+		}
+
+		result, err := renderer.RenderToMarkdown(node)
+		if err != nil {
+			t.Fatalf("RenderToMarkdown failed: %v", err)
+		}
+
+		if result != markdown {
+			t.Errorf("\nExpected: %q\nGot:      %q", markdown, result)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package adf2md
+
+import (
+	"html"
+	"sort"
+	"strings"
+)
+
+// builtinMarkRenderers holds the built-in delimiters for each mark type.
+// Marks without an entry are dropped and their text written unformatted.
+var builtinMarkRenderers = map[string]MarkRenderer{
+	"strong": delimiters("**", "**"),
+	"em":     delimiters("*", "*"),
+	"strike": delimiters("~~", "~~"),
+	// Markdown has no underline, and "_" would read as emphasis
+	"underline": htmlMark("<u>", "</u>"),
+	"code": MarkRendererFunc(func(ctx *RenderContext, mark *Mark, text string) (string, string) {
+		if ctx.Options().DisableEscaping {
+			return "`", "`"
+		}
+		return codeSpanDelimiters(text)
+	}),
+	"link": MarkRendererFunc(func(ctx *RenderContext, mark *Mark, text string) (string, string) {
+		href, ok := mark.Attrs["href"].(string)
+		if !ok {
+			ctx.w.state.warnMark(WarningMissingAttr, mark, "link has no href")
+			return "", ""
+		}
+		if !ctx.Options().DisableEscaping {
+			href = linkDestination(href)
+		}
+		if title, ok := mark.Attrs["title"].(string); ok && title != "" {
+			href += ` "` + strings.ReplaceAll(title, `"`, `\"`) + `"`
+		}
+		return "[", "](" + href + ")"
+	}),
+	"textColor":       styleSpan("color"),
+	"backgroundColor": styleSpan("background-color"),
+	"subsup": MarkRendererFunc(func(ctx *RenderContext, mark *Mark, text string) (string, string) {
+		switch mark.Attrs["type"] {
+		case "sub":
+			warnInlineHTML(ctx, mark)
+			return "<sub>", "</sub>"
+		case "sup":
+			warnInlineHTML(ctx, mark)
+			return "<sup>", "</sup>"
+		}
+		ctx.w.state.warnMark(WarningMissingAttr, mark, "subsup has no type")
+		return "", ""
+	}),
+}
+
+// delimiters returns a MarkRenderer that always wraps text in open and close
+func delimiters(open, close string) MarkRenderer {
+	return MarkRendererFunc(func(ctx *RenderContext, mark *Mark, text string) (string, string) {
+		return open, close
+	})
+}
+
+// htmlMark returns a MarkRenderer that wraps text in inline HTML tags
+func htmlMark(open, close string) MarkRenderer {
+	return MarkRendererFunc(func(ctx *RenderContext, mark *Mark, text string) (string, string) {
+		warnInlineHTML(ctx, mark)
+		return open, close
+	})
+}
+
+// warnInlineHTML records that a mark was written as inline HTML, which
+// many Markdown viewers strip out
+func warnInlineHTML(ctx *RenderContext, mark *Mark) {
+	ctx.w.state.warnMark(WarningLossy, mark, mark.Type+" written as inline HTML")
+}
+
+// styleSpan returns a MarkRenderer that wraps text in an HTML span setting
+// a CSS property to the mark's color attribute
+func styleSpan(property string) MarkRenderer {
+	return MarkRendererFunc(func(ctx *RenderContext, mark *Mark, text string) (string, string) {
+		color, ok := mark.Attrs["color"].(string)
+		if !ok || color == "" {
+			ctx.w.state.warnMark(WarningMissingAttr, mark, mark.Type+" has no color")
+			return "", ""
+		}
+		warnInlineHTML(ctx, mark)
+		return `<span style="` + property + ": " + html.EscapeString(color) + `">`, "</span>"
+	})
+}
+
+// markRank orders marks from outermost to innermost. Links go outside so
+// their label can hold any formatting, and code goes inside because
+// nothing is parsed within a code span.
+func markRank(markType string) int {
+	switch markType {
+	case "link":
+		return 0
+	case "code":
+		return 2
+	default:
+		return 1
+	}
+}
+
+// orderMarks returns marks sorted from outermost to innermost, keeping the
+// document order of marks with the same rank
+func orderMarks(marks []Mark) []Mark {
+	sorted := true
+	for i := 1; i < len(marks); i++ {
+		if markRank(marks[i].Type) < markRank(marks[i-1].Type) {
+			sorted = false
+			break
+		}
+	}
+	if sorted {
+		return marks
+	}
+
+	ordered := make([]Mark, len(marks))
+	copy(ordered, marks)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return markRank(ordered[i].Type) < markRank(ordered[j].Type)
+	})
+	return ordered
+}
+
+// markDelimiters returns the delimiters for a mark, using a registered
+// MarkRenderer if there is one for its type
+func (r *Renderer) markDelimiters(ctx *RenderContext, mark *Mark, text string) (string, string) {
+	markRenderer, ok := r.markRenderers[mark.Type]
+	if !ok {
+		markRenderer, ok = r.builtinMark(mark.Type)
+	}
+	if !ok {
+		return "", ""
+	}
+	return markRenderer.RenderMark(ctx, mark, text)
+}
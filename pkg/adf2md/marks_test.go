@@ -0,0 +1,180 @@
+package adf2md_test
+
+import (
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+// paragraphWithText builds a document holding a single paragraph of text nodes
+func paragraphWithText(texts ...string) string {
+	content := ""
+	for i, text := range texts {
+		if i > 0 {
+			content += ","
+		}
+		content += text
+	}
+	return `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[` + content + `]}]}`
+}
+
+func TestRenderMarks(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Link outside of emphasis",
+			input:    paragraphWithText(`{"type":"text","text":"both","marks":[{"type":"strong"},{"type":"link","attrs":{"href":"https://example.com"}}]}`),
+			expected: "[**both**](https://example.com)\n\n",
+		},
+		{
+			name:     "Code inside of link and emphasis",
+			input:    paragraphWithText(`{"type":"text","text":"f()","marks":[{"type":"code"},{"type":"em"},{"type":"link","attrs":{"href":"https://example.com"}}]}`),
+			expected: "[*`f()`*](https://example.com)\n\n",
+		},
+		{
+			name:     "Document order kept for emphasis",
+			input:    paragraphWithText(`{"type":"text","text":"a","marks":[{"type":"strike"},{"type":"strong"}]}`),
+			expected: "~~**a**~~\n\n",
+		},
+		{
+			name:     "Whitespace moved outside of delimiters",
+			input:    paragraphWithText(`{"type":"text","text":"plain"}`, `{"type":"text","text":" bold ","marks":[{"type":"strong"}]}`, `{"type":"text","text":"after"}`),
+			expected: "plain **bold** after\n\n",
+		},
+		{
+			name:     "Whitespace-only marked text",
+			input:    paragraphWithText(`{"type":"text","text":"a"}`, `{"type":"text","text":" ","marks":[{"type":"em"}]}`, `{"type":"text","text":"b"}`),
+			expected: "a b\n\n",
+		},
+		{
+			name:     "Code spans keep their whitespace",
+			input:    paragraphWithText(`{"type":"text","text":" x ","marks":[{"type":"code"}]}`),
+			expected: "` x `\n\n",
+		},
+		{
+			name:     "Link title",
+			input:    paragraphWithText(`{"type":"text","text":"docs","marks":[{"type":"link","attrs":{"href":"https://example.com","title":"The \"docs\""}}]}`),
+			expected: "[docs](https://example.com \"The \\\"docs\\\"\")\n\n",
+		},
+		{
+			name:     "Underline",
+			input:    paragraphWithText(`{"type":"text","text":"under","marks":[{"type":"underline"}]}`),
+			expected: "<u>under</u>\n\n",
+		},
+		{
+			name:     "Colors",
+			input:    paragraphWithText(`{"type":"text","text":"red","marks":[{"type":"textColor","attrs":{"color":"#ff5630"}},{"type":"backgroundColor","attrs":{"color":"#fedec8"}}]}`),
+			expected: "<span style=\"color: #ff5630\"><span style=\"background-color: #fedec8\">red</span></span>\n\n",
+		},
+		{
+			name:     "Subscript and superscript",
+			input:    paragraphWithText(`{"type":"text","text":"H"}`, `{"type":"text","text":"2","marks":[{"type":"subsup","attrs":{"type":"sub"}}]}`, `{"type":"text","text":"O x"}`, `{"type":"text","text":"2","marks":[{"type":"subsup","attrs":{"type":"sup"}}]}`),
+			expected: "H<sub>2</sub>O x<sup>2</sup>\n\n",
+		},
+		{
+			name:     "Unknown marks are dropped",
+			input:    paragraphWithText(`{"type":"text","text":"# text","marks":[{"type":"fancy"}]}`),
+			expected: "\\# text\n\n",
+		},
+	}
+
+	renderer := adf2md.NewRenderer()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := adf2md.ParseADF(tt.input)
+			if err != nil {
+				t.Fatalf("Failed to parse ADF: %v", err)
+			}
+
+			result, err := renderer.RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRegisterMark(t *testing.T) {
+	input := paragraphWithText(
+		`{"type":"text","text":"under","marks":[{"type":"underline"}]}`,
+		`{"type":"text","text":" "}`,
+		`{"type":"text","text":"red","marks":[{"type":"strong"},{"type":"textColor","attrs":{"color":"#ff5630"}}]}`,
+		`{"type":"text","text":" "}`,
+		`{"type":"text","text":"key","marks":[{"type":"kbd"},{"type":"link","attrs":{"href":"https://example.com"}}]}`,
+	)
+
+	tests := []struct {
+		name     string
+		markType string
+		renderer adf2md.MarkRenderer
+		expected string
+	}{
+		{
+			name:     "Replace a built-in mark",
+			markType: "underline",
+			renderer: adf2md.MarkRendererFunc(func(ctx *adf2md.RenderContext, mark *adf2md.Mark, text string) (string, string) {
+				return "<ins>", "</ins>"
+			}),
+			expected: "<ins>under</ins> **<span style=\"color: #ff5630\">red</span>** [key](https://example.com)\n\n",
+		},
+		{
+			name:     "Drop a built-in mark",
+			markType: "textColor",
+			renderer: adf2md.PlainMarkRenderer,
+			expected: "<u>under</u> **red** [key](https://example.com)\n\n",
+		},
+		{
+			name:     "Wrap the built-in renderer",
+			markType: "strong",
+			renderer: adf2md.MarkRendererFunc(func(ctx *adf2md.RenderContext, mark *adf2md.Mark, text string) (string, string) {
+				open, close := adf2md.DefaultMarkRenderer.RenderMark(ctx, mark, text)
+				return "(" + open, close + ")"
+			}),
+			expected: "<u>under</u> (**<span style=\"color: #ff5630\">red</span>**) [key](https://example.com)\n\n",
+		},
+		{
+			name:     "Custom mark nested inside a link",
+			markType: "kbd",
+			renderer: adf2md.MarkRendererFunc(func(ctx *adf2md.RenderContext, mark *adf2md.Mark, text string) (string, string) {
+				return "<kbd>", "</kbd>"
+			}),
+			expected: "<u>under</u> **<span style=\"color: #ff5630\">red</span>** [<kbd>key</kbd>](https://example.com)\n\n",
+		},
+		{
+			name:     "Registering nil restores the built-in renderer",
+			markType: "underline",
+			renderer: nil,
+			expected: "<u>under</u> **<span style=\"color: #ff5630\">red</span>** [key](https://example.com)\n\n",
+		},
+	}
+
+	node, err := adf2md.ParseADF(input)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := adf2md.NewRenderer().
+				RegisterMark(tt.markType, adf2md.PlainMarkRenderer).
+				RegisterMark(tt.markType, tt.renderer)
+
+			result, err := renderer.RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
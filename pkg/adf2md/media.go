@@ -0,0 +1,196 @@
+package adf2md
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Media describes an attachment referenced by a media node, for a
+// MediaResolver to turn into a URL
+type Media struct {
+	// The media node itself
+	Node *Node
+	// The media services file ID
+	ID string
+	// The collection the file belongs to, e.g. "contentId-123456" for an
+	// attachment on the Confluence page with ID 123456
+	Collection string
+	// "file", "link" or "external"
+	Type string
+	// The url attribute of external media, which have no ID
+	URL string
+	// Dimensions in pixels, or 0 if unknown
+	Width  int
+	Height int
+	// The alternative text, which usually holds the file name
+	Alt string
+	// The file name, from the __fileName attribute, if it is recorded
+	// apart from the alternative text
+	FileName string
+}
+
+// MediaResolver works out the URL of an attachment. External media are
+// passed to it too, with their URL set, and link to that URL if it returns
+// false.
+type MediaResolver interface {
+	// ResolveMedia returns the URL of the attachment, or false if it can't
+	// be located
+	ResolveMedia(media Media) (string, bool)
+}
+
+// MediaResolverFunc adapts an ordinary function to the MediaResolver
+// interface
+type MediaResolverFunc func(media Media) (string, bool)
+
+// ResolveMedia calls f(media)
+func (f MediaResolverFunc) ResolveMedia(media Media) (string, bool) {
+	return f(media)
+}
+
+// ConfluenceMediaResolver returns a MediaResolver for attachments on
+// Confluence Cloud pages, linking to
+// <baseURL>/wiki/download/attachments/<page id>/<file name>. The page ID
+// comes from the collection and the file name from the alt text, falling
+// back to the file ID.
+func ConfluenceMediaResolver(baseURL string) MediaResolver {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return MediaResolverFunc(func(media Media) (string, bool) {
+		if media.ID == "" {
+			return "", false
+		}
+		pageID := strings.TrimPrefix(media.Collection, "contentId-")
+		if pageID == "" {
+			return "", false
+		}
+		return baseURL + "/wiki/download/attachments/" + url.PathEscape(pageID) + "/" + url.PathEscape(fileName(media)), true
+	})
+}
+
+// JiraMediaResolver returns a MediaResolver for attachments on Jira Cloud
+// issues, linking to <baseURL>/secure/attachment/<id>/<file name>
+func JiraMediaResolver(baseURL string) MediaResolver {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return MediaResolverFunc(func(media Media) (string, bool) {
+		if media.ID == "" {
+			return "", false
+		}
+		return baseURL + "/secure/attachment/" + url.PathEscape(media.ID) + "/" + url.PathEscape(fileName(media)), true
+	})
+}
+
+// TemplateMediaResolver returns a MediaResolver that fills in a URL
+// template, where {id}, {collection}, {type}, {alt}, {width} and {height}
+// are replaced with the media's details, e.g.
+// "https://files.example.com/{collection}/{id}". Values are escaped for
+// use in a URL path.
+func TemplateMediaResolver(template string) MediaResolver {
+	return MediaResolverFunc(func(media Media) (string, bool) {
+		if media.ID == "" {
+			return "", false
+		}
+		return strings.NewReplacer(
+			"{id}", url.PathEscape(media.ID),
+			"{collection}", url.PathEscape(media.Collection),
+			"{type}", url.PathEscape(media.Type),
+			"{alt}", url.PathEscape(media.Alt),
+			"{width}", strconv.Itoa(media.Width),
+			"{height}", strconv.Itoa(media.Height),
+		).Replace(template), true
+	})
+}
+
+// fileName returns the file name of an attachment, which Atlassian
+// products usually store as the alt text
+func fileName(media Media) string {
+	if media.FileName != "" {
+		return media.FileName
+	}
+	if media.Alt != "" {
+		return media.Alt
+	}
+	return media.ID
+}
+
+// mediaURL returns the URL of a media node, from the MediaResolver if
+// there is one. Otherwise external media link to their url attribute and
+// attachments to /wiki/download/attachments/<collection>/<id>, as long as
+// their collection is known. External media also link to their url
+// attribute if the resolver declines them.
+func (o *RenderOptions) mediaURL(node *Node) (string, bool) {
+	media := nodeMedia(node)
+	if o.MediaResolver != nil {
+		if url, ok := o.MediaResolver.ResolveMedia(media); ok {
+			return url, true
+		}
+		return media.URL, media.URL != ""
+	}
+
+	if media.URL != "" {
+		return media.URL, true
+	}
+	if media.ID == "" || media.Collection == "" {
+		return "", false
+	}
+	return "/wiki/download/attachments/" + media.Collection + "/" + media.ID, true
+}
+
+// nodeMedia returns the details of a media node
+func nodeMedia(node *Node) Media {
+	media := Media{Node: node}
+	media.Type, _ = node.Attrs["type"].(string)
+	if media.Type == "external" {
+		media.URL, _ = node.Attrs["url"].(string)
+	}
+	media.ID, _ = node.Attrs["id"].(string)
+	media.Collection, _ = node.Attrs["collection"].(string)
+	media.Alt, _ = node.Attrs["alt"].(string)
+	media.FileName, _ = node.Attrs["__fileName"].(string)
+	media.Width, _ = intAttr(node.Attrs, "width")
+	media.Height, _ = intAttr(node.Attrs, "height")
+	return media
+}
+
+// renderMediaGroup renders a mediaGroup node, which holds attachments
+// shown as file cards, as a list of links
+func (r *Renderer) renderMediaGroup(w *markdownWriter, node *Node) {
+	for i := range node.Content {
+		media := &node.Content[i]
+		if media.Type != "media" {
+			continue
+		}
+
+		w.ensureNewlines(1)
+		w.pushPrefix("* ", strings.Repeat(" ", r.options.ListIndent))
+		w.state.enter(media)
+		r.renderMediaLink(w, media)
+		w.state.leave()
+		w.popPrefix()
+	}
+	w.ensureNewlines(2)
+}
+
+// renderMediaLink renders a media node as a link to the file, labelled
+// with its name
+func (r *Renderer) renderMediaLink(w *markdownWriter, node *Node) {
+	name, _ := node.Attrs["alt"].(string)
+	if name == "" {
+		name, _ = node.Attrs["id"].(string)
+	}
+	if name == "" {
+		name = "attachment"
+	}
+
+	url, ok := r.options.mediaURL(node)
+	if !ok {
+		w.state.warn(WarningUnresolvedMedia, node.Type+" has no URL or id to link to")
+		w.WriteString("[Attachment: " + name + "]")
+		return
+	}
+
+	if !r.options.DisableEscaping {
+		name = escapeText(name, escapeLinkLabel)
+		url = linkDestination(url)
+	}
+	w.WriteString("[" + name + "](" + url + ")")
+}
@@ -0,0 +1,74 @@
+package adf2md_test
+
+import (
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestRenderMedia(t *testing.T) {
+	input := `{"version":1,"type":"doc","content":[
+		{"type":"mediaSingle","content":[
+			{"type":"media","attrs":{"type":"file","id":"abc-123","collection":"contentId-98765","alt":"diagram one.png","width":640,"height":480}}
+		]},
+		{"type":"mediaGroup","content":[
+			{"type":"media","attrs":{"type":"file","id":"def-456","collection":"contentId-98765","alt":"notes.pdf"}}
+		]},
+		{"type":"mediaSingle","content":[
+			{"type":"media","attrs":{"type":"external","url":"https://example.com/logo.png"}}
+		]}
+	]}`
+
+	tests := []struct {
+		name     string
+		options  adf2md.RenderOptions
+		expected string
+	}{
+		{
+			name:     "Without a resolver",
+			options:  adf2md.RenderOptions{},
+			expected: "![diagram one.png](/wiki/download/attachments/contentId-98765/abc-123)\n\n* [notes.pdf](/wiki/download/attachments/contentId-98765/def-456)\n\n![image](https://example.com/logo.png)\n\n",
+		},
+		{
+			name:     "Confluence Cloud",
+			options:  adf2md.RenderOptions{MediaResolver: adf2md.ConfluenceMediaResolver("https://example.atlassian.net/")},
+			expected: "![diagram one.png](https://example.atlassian.net/wiki/download/attachments/98765/diagram%20one.png)\n\n* [notes.pdf](https://example.atlassian.net/wiki/download/attachments/98765/notes.pdf)\n\n![image](https://example.com/logo.png)\n\n",
+		},
+		{
+			name:     "Jira Cloud",
+			options:  adf2md.RenderOptions{MediaResolver: adf2md.JiraMediaResolver("https://example.atlassian.net")},
+			expected: "![diagram one.png](https://example.atlassian.net/secure/attachment/abc-123/diagram%20one.png)\n\n* [notes.pdf](https://example.atlassian.net/secure/attachment/def-456/notes.pdf)\n\n![image](https://example.com/logo.png)\n\n",
+		},
+		{
+			name:     "Template",
+			options:  adf2md.RenderOptions{MediaResolver: adf2md.TemplateMediaResolver("https://files.example.com/{collection}/{id}?w={width}&h={height}")},
+			expected: "![diagram one.png](https://files.example.com/contentId-98765/abc-123?w=640&h=480)\n\n* [notes.pdf](https://files.example.com/contentId-98765/def-456?w=0&h=0)\n\n![image](https://example.com/logo.png)\n\n",
+		},
+		{
+			name: "Unresolved",
+			options: adf2md.RenderOptions{MediaResolver: adf2md.MediaResolverFunc(func(media adf2md.Media) (string, bool) {
+				return "", false
+			})},
+			expected: "[Image: diagram one.png - Type: file]\n\n* [Attachment: notes.pdf]\n\n![image](https://example.com/logo.png)\n\n",
+		},
+	}
+
+	node, err := adf2md.ParseADF(input)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := adf2md.NewRenderer().WithOptions(tt.options)
+			result, err := renderer.RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
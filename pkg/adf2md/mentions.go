@@ -0,0 +1,206 @@
+package adf2md
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// User holds what is known about a mentioned account
+type User struct {
+	// The Atlassian account ID
+	ID string `json:"id"`
+	// The display name
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	// A link to the user's profile
+	URL string `json:"url,omitempty"`
+	// The Slack member ID, such as U0123ABCD, for mentions in Slack
+	// messages
+	SlackID string `json:"slackId,omitempty"`
+}
+
+// MentionResolver looks up the user behind a mention's account ID
+type MentionResolver interface {
+	// ResolveMention returns the user with the given account ID, or false
+	// if the user isn't known
+	ResolveMention(id string) (User, bool)
+}
+
+// MentionResolverFunc adapts an ordinary function to the MentionResolver
+// interface
+type MentionResolverFunc func(id string) (User, bool)
+
+// ResolveMention calls f(id)
+func (f MentionResolverFunc) ResolveMention(id string) (User, bool) {
+	return f(id)
+}
+
+// UserMap is a MentionResolver backed by a fixed set of users, keyed by
+// account ID
+type UserMap map[string]User
+
+// ResolveMention returns the user with the given account ID
+func (m UserMap) ResolveMention(id string) (User, bool) {
+	user, ok := m[id]
+	return user, ok
+}
+
+// ReadUserMapCSV reads a UserMap from CSV. The first row names the
+// columns, which can be id, name, email, url and slack_id in any order.
+// Only id is required.
+func ReadUserMapCSV(r io.Reader) (UserMap, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return UserMap{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading user map: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["id"]; !ok {
+		return nil, errors.New("error reading user map: no id column")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	users := UserMap{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return users, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading user map: %w", err)
+		}
+
+		user := User{
+			ID:      field(record, "id"),
+			Name:    field(record, "name"),
+			Email:   field(record, "email"),
+			URL:     field(record, "url"),
+			SlackID: field(record, "slack_id"),
+		}
+		if user.ID != "" {
+			users[user.ID] = user
+		}
+	}
+}
+
+// ReadUserMapJSON reads a UserMap from JSON. This is either an array of
+// users or an object mapping account IDs to users. Users have id, name,
+// email, url and slackId fields; the accountId, displayName and
+// emailAddress fields returned by the Jira and Confluence REST APIs are
+// also understood.
+func ReadUserMapJSON(r io.Reader) (UserMap, error) {
+	var data any
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, fmt.Errorf("error reading user map: %w", err)
+	}
+
+	users := UserMap{}
+	switch data := data.(type) {
+	case []any:
+		for _, item := range data {
+			if fields, ok := item.(map[string]any); ok {
+				user := jsonUser(fields, "")
+				if user.ID != "" {
+					users[user.ID] = user
+				}
+			}
+		}
+	case map[string]any:
+		for id, item := range data {
+			if fields, ok := item.(map[string]any); ok {
+				users[id] = jsonUser(fields, id)
+			}
+		}
+	default:
+		return nil, errors.New("error reading user map: expected an array or object of users")
+	}
+	return users, nil
+}
+
+// jsonUser builds a User from decoded JSON fields, using id if the fields
+// don't include one
+func jsonUser(fields map[string]any, id string) User {
+	first := func(names ...string) string {
+		for _, name := range names {
+			if value, ok := fields[name].(string); ok && value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	user := User{
+		ID:      first("id", "accountId"),
+		Name:    first("name", "displayName"),
+		Email:   first("email", "emailAddress"),
+		URL:     first("url"),
+		SlackID: first("slackId", "slack_id"),
+	}
+	if user.ID == "" {
+		user.ID = id
+	}
+	return user
+}
+
+// mentionUser returns the user for a mention node, filled in by the
+// MentionResolver if there is one
+func (o *RenderOptions) mentionUser(node *Node) User {
+	id, _ := node.Attrs["id"].(string)
+	text, _ := node.Attrs["text"].(string)
+
+	// Mention text usually already starts with the @
+	user := User{ID: id, Name: strings.TrimPrefix(text, "@")}
+
+	if o.MentionResolver == nil || id == "" {
+		return user
+	}
+	resolved, ok := o.MentionResolver.ResolveMention(id)
+	if !ok {
+		return user
+	}
+	if resolved.Name == "" {
+		resolved.Name = user.Name
+	}
+	resolved.ID = id
+	return resolved
+}
+
+// formatMention fills in the MentionTemplate for a user. The name and
+// email are escaped as text, while the id and url are written as they are
+// so they can be used in link destinations.
+func (r *Renderer) formatMention(user User) string {
+	name, email := user.Name, user.Email
+	if name == "" {
+		name = user.ID
+	}
+	if !r.options.DisableEscaping {
+		name = escapeText(name, escapeLinkLabel)
+		email = escapeText(email, escapeLinkLabel)
+	}
+
+	return strings.NewReplacer(
+		"{id}", user.ID,
+		"{name}", name,
+		"{email}", email,
+		"{url}", user.URL,
+	).Replace(r.options.MentionTemplate)
+}
@@ -0,0 +1,127 @@
+package adf2md_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestRenderMentions(t *testing.T) {
+	input := `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[
+		{"type":"mention","attrs":{"id":"557058:1","text":"@Jane Doe"}},
+		{"type":"text","text":" "},
+		{"type":"mention","attrs":{"id":"557058:2"}},
+		{"type":"text","text":" "},
+		{"type":"mention","attrs":{"id":"557058:3","text":"@old_name_"}}
+	]}]}`
+
+	users := adf2md.UserMap{
+		"557058:2": {ID: "557058:2", Name: "Sam *S* Lee", Email: "sam@example.com", URL: "https://example.atlassian.net/people/557058:2"},
+	}
+
+	tests := []struct {
+		name     string
+		options  adf2md.RenderOptions
+		expected string
+	}{
+		{
+			name:     "Without a resolver",
+			options:  adf2md.RenderOptions{},
+			expected: "@Jane Doe @user:557058:2 @old_name\\_\n\n",
+		},
+		{
+			name:     "With a resolver",
+			options:  adf2md.RenderOptions{MentionResolver: users},
+			expected: "@Jane Doe @Sam \\*S\\* Lee @old_name\\_\n\n",
+		},
+		{
+			name: "With a template",
+			options: adf2md.RenderOptions{
+				MentionResolver: users,
+				MentionTemplate: "[@{name}](https://example.atlassian.net/people/{id})",
+			},
+			expected: "[@Jane Doe](https://example.atlassian.net/people/557058:1) [@Sam \\*S\\* Lee](https://example.atlassian.net/people/557058:2) [@old_name\\_](https://example.atlassian.net/people/557058:3)\n\n",
+		},
+		{
+			name: "With a resolver function and email template",
+			options: adf2md.RenderOptions{
+				MentionResolver: adf2md.MentionResolverFunc(func(id string) (adf2md.User, bool) {
+					return adf2md.User{Email: strings.TrimPrefix(id, "557058:") + "@example.com"}, true
+				}),
+				MentionTemplate: "<{email}>",
+			},
+			expected: "<1@example.com> <2@example.com> <3@example.com>\n\n",
+		},
+	}
+
+	node, err := adf2md.ParseADF(input)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := adf2md.NewRenderer().WithOptions(tt.options)
+			result, err := renderer.RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestReadUserMap(t *testing.T) {
+	expected := adf2md.UserMap{
+		"1": {ID: "1", Name: "Jane Doe", Email: "jane@example.com", SlackID: "U0123ABCD"},
+		"2": {ID: "2", Name: "Sam Lee", URL: "https://example.com/sam"},
+	}
+
+	tests := []struct {
+		name  string
+		read  func(string) (adf2md.UserMap, error)
+		input string
+	}{
+		{
+			name:  "CSV",
+			read:  func(s string) (adf2md.UserMap, error) { return adf2md.ReadUserMapCSV(strings.NewReader(s)) },
+			input: "Name,ID,Email,URL,Slack_ID\nJane Doe,1,jane@example.com,,U0123ABCD\n\"Sam Lee\",2,,https://example.com/sam\n",
+		},
+		{
+			name:  "JSON array",
+			read:  func(s string) (adf2md.UserMap, error) { return adf2md.ReadUserMapJSON(strings.NewReader(s)) },
+			input: `[{"id":"1","name":"Jane Doe","email":"jane@example.com","slackId":"U0123ABCD"},{"id":"2","name":"Sam Lee","url":"https://example.com/sam"}]`,
+		},
+		{
+			name:  "JSON object",
+			read:  func(s string) (adf2md.UserMap, error) { return adf2md.ReadUserMapJSON(strings.NewReader(s)) },
+			input: `{"1":{"name":"Jane Doe","email":"jane@example.com","slack_id":"U0123ABCD"},"2":{"name":"Sam Lee","url":"https://example.com/sam"}}`,
+		},
+		{
+			name:  "Atlassian REST API users",
+			read:  func(s string) (adf2md.UserMap, error) { return adf2md.ReadUserMapJSON(strings.NewReader(s)) },
+			input: `[{"accountId":"1","displayName":"Jane Doe","emailAddress":"jane@example.com","active":true,"slackId":"U0123ABCD"},{"accountId":"2","displayName":"Sam Lee","url":"https://example.com/sam"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := tt.read(tt.input)
+			if err != nil {
+				t.Fatalf("Failed to read user map: %v", err)
+			}
+			if !reflect.DeepEqual(users, expected) {
+				t.Errorf("\nExpected: %+v\nGot:      %+v", expected, users)
+			}
+		})
+	}
+
+	if _, err := adf2md.ReadUserMapCSV(strings.NewReader("name,email\nJane,jane@example.com\n")); err == nil {
+		t.Error("Expected an error for a CSV user map without an id column")
+	}
+}
@@ -0,0 +1,107 @@
+package adf2md
+
+import "fmt"
+
+// PanelStyle controls how panels (info, note, warning and similar boxes)
+// are rendered
+type PanelStyle int
+
+const (
+	// PanelStyleLegacy renders a blockquote headed by the panel type, such
+	// as **Panel (info)**
+	PanelStyleLegacy PanelStyle = iota
+	// PanelStyleGitHub renders a GitHub alert such as > [!NOTE]
+	PanelStyleGitHub
+	// PanelStyleObsidian renders an Obsidian callout such as > [!info],
+	// titled with the icon of custom panels
+	PanelStyleObsidian
+	// PanelStyleAdmonition renders a :::note fenced admonition as used by
+	// Docusaurus and MkDocs, titled with the icon of custom panels
+	PanelStyleAdmonition
+)
+
+// panelTypes maps each panelType to the closest GitHub alert, Obsidian
+// callout and admonition type
+var panelTypes = map[string]struct{ github, obsidian, admonition string }{
+	"info":    {"NOTE", "info", "info"},
+	"note":    {"IMPORTANT", "note", "note"},
+	"warning": {"WARNING", "warning", "warning"},
+	"error":   {"CAUTION", "danger", "danger"},
+	"success": {"TIP", "success", "tip"},
+	"tip":     {"TIP", "tip", "tip"},
+	"custom":  {"NOTE", "note", "note"},
+}
+
+// renderPanel renders a panel node in the configured PanelStyle
+func (r *Renderer) renderPanel(w *markdownWriter, node *Node) {
+	panelType, _ := node.Attrs["panelType"].(string)
+
+	if r.options.PanelStyle == PanelStyleLegacy {
+		w.pushPrefix("> ", "> ")
+		w.WriteString(fmt.Sprintf("**Panel (%s)**", panelType))
+		w.ensureNewlines(1)
+		r.renderContent(w, node.Content)
+		w.popPrefix()
+		w.ensureNewlines(2)
+		return
+	}
+
+	types, ok := panelTypes[panelType]
+	if !ok {
+		types = panelTypes["info"]
+	}
+
+	// Only custom panels have their own icon and color
+	var icon string
+	if panelType == "custom" {
+		icon = panelIcon(node)
+		if color, _ := node.Attrs["panelColor"].(string); color != "" {
+			w.state.warn(WarningLossy, "panel color "+color+" dropped")
+		}
+		if icon != "" && r.options.PanelStyle == PanelStyleGitHub {
+			w.state.warn(WarningLossy, "panel icon dropped from GitHub alert")
+		}
+	}
+
+	switch r.options.PanelStyle {
+	case PanelStyleAdmonition:
+		w.WriteString(":::" + types.admonition)
+		if icon != "" {
+			w.WriteString("[" + icon + "]")
+		}
+		w.ensureNewlines(1)
+		r.renderContent(w, node.Content)
+		w.trimNewlines(1)
+		w.ensureNewlines(1)
+		w.WriteString(":::")
+
+	case PanelStyleObsidian:
+		w.pushPrefix("> ", "> ")
+		w.WriteString("[!" + types.obsidian + "]")
+		if icon != "" {
+			w.WriteString(" " + icon)
+		}
+		w.ensureNewlines(1)
+		r.renderContent(w, node.Content)
+		w.popPrefix()
+
+	default:
+		w.pushPrefix("> ", "> ")
+		w.WriteString("[!" + types.github + "]")
+		w.ensureNewlines(1)
+		r.renderContent(w, node.Content)
+		w.popPrefix()
+	}
+
+	w.ensureNewlines(2)
+}
+
+// panelIcon returns the emoji of a custom panel's icon, or its short name
+// if the emoji isn't stored
+func panelIcon(node *Node) string {
+	if text, _ := node.Attrs["panelIconText"].(string); text != "" {
+		return text
+	}
+	icon, _ := node.Attrs["panelIcon"].(string)
+	return icon
+}
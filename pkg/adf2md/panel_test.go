@@ -0,0 +1,63 @@
+package adf2md_test
+
+import (
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestRenderPanels(t *testing.T) {
+	input := `{"version":1,"type":"doc","content":[
+		{"type":"panel","attrs":{"panelType":"warning"},"content":[{"type":"paragraph","content":[{"type":"text","text":"Mind the gap"}]}]},
+		{"type":"panel","attrs":{"panelType":"custom","panelIcon":":tada:","panelIconText":"🎉","panelColor":"#eae6ff"},"content":[
+			{"type":"paragraph","content":[{"type":"text","text":"Shipped"}]},
+			{"type":"paragraph","content":[{"type":"text","text":"Thanks all"}]}
+		]}
+	]}`
+
+	tests := []struct {
+		name     string
+		style    adf2md.PanelStyle
+		expected string
+	}{
+		{
+			name:     "Legacy",
+			style:    adf2md.PanelStyleLegacy,
+			expected: "> **Panel (warning)**\n> Mind the gap\n\n> **Panel (custom)**\n> Shipped\n>\n> Thanks all\n\n",
+		},
+		{
+			name:     "GitHub alerts",
+			style:    adf2md.PanelStyleGitHub,
+			expected: "> [!WARNING]\n> Mind the gap\n\n> [!NOTE]\n> Shipped\n>\n> Thanks all\n\n",
+		},
+		{
+			name:     "Obsidian callouts",
+			style:    adf2md.PanelStyleObsidian,
+			expected: "> [!warning]\n> Mind the gap\n\n> [!note] 🎉\n> Shipped\n>\n> Thanks all\n\n",
+		},
+		{
+			name:     "Admonitions",
+			style:    adf2md.PanelStyleAdmonition,
+			expected: ":::warning\nMind the gap\n:::\n\n:::note[🎉]\nShipped\n\nThanks all\n:::\n\n",
+		},
+	}
+
+	node, err := adf2md.ParseADF(input)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := adf2md.NewRenderer().WithOptions(adf2md.RenderOptions{PanelStyle: tt.style})
+			result, err := renderer.RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
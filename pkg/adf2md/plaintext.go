@@ -0,0 +1,538 @@
+package adf2md
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Numbering controls how the items of ordered lists are numbered in plain
+// text
+type Numbering int
+
+const (
+	// NumberingDecimal numbers items 1., 2., 3. in every list
+	NumberingDecimal Numbering = iota
+	// NumberingOutline numbers nested items after the item they belong
+	// to, such as 2.1.
+	NumberingOutline
+	// NumberingNone writes the items of ordered lists with bullets
+	NumberingNone
+)
+
+// LinkStyle controls how links are written in plain text
+type LinkStyle int
+
+const (
+	// LinkStyleInline writes the URL in parentheses after the link text,
+	// such as "the docs (https://example.com/docs)"
+	LinkStyleInline LinkStyle = iota
+	// LinkStyleTextOnly writes only the link text
+	LinkStyleTextOnly
+)
+
+// DefaultBullets are the glyphs bullet list items are marked with when
+// PlainTextOptions.Bullets is empty, one for each level of nesting
+var DefaultBullets = []string{"•", "◦", "▪"}
+
+// PlainTextOptions contains configuration for the plain text rendering
+type PlainTextOptions struct {
+	// Options shared with the Markdown renderer: the mention and media
+	// resolvers, mention template, date and status styles, layout
+	// separator and Strict. Options that only apply to Markdown syntax are
+	// ignored.
+	RenderOptions
+
+	// Wrap paragraphs so lines are at most this many characters long,
+	// counting indentation. Lines aren't wrapped if it is 0.
+	Width int
+
+	// Glyphs bullet list items are marked with, one for each level of
+	// nesting. Deeper levels start again from the first. Defaults to
+	// DefaultBullets.
+	Bullets []string
+
+	// How ordered list items are numbered (defaults to NumberingDecimal)
+	Numbering Numbering
+
+	// How links are written (defaults to LinkStyleInline)
+	LinkStyle LinkStyle
+}
+
+// PlainTextRenderer handles converting ADF nodes to readable plain text
+// with no markup, for emails, notification previews and search indexes.
+// Formatting is dropped, lists are marked with bullet glyphs and numbers,
+// and tables are laid out in aligned columns.
+type PlainTextRenderer struct {
+	// Options for customizing the plain text output
+	options PlainTextOptions
+}
+
+// NewPlainTextRenderer creates a new plain text renderer with default
+// options
+func NewPlainTextRenderer() *PlainTextRenderer {
+	return &PlainTextRenderer{}
+}
+
+// WithOptions returns a new PlainTextRenderer with the specified options
+func (p *PlainTextRenderer) WithOptions(options PlainTextOptions) *PlainTextRenderer {
+	p.options = options
+	return p
+}
+
+// RenderToText converts an ADF node to plain text
+func (p *PlainTextRenderer) RenderToText(node *Node) (string, error) {
+	var result strings.Builder
+	if _, err := p.renderer().render(&result, node); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// Render converts an ADF node to plain text, writing the output to w as it
+// is produced rather than building it up in memory
+func (p *PlainTextRenderer) Render(w io.Writer, node *Node) error {
+	out := bufio.NewWriter(w)
+	if _, err := p.renderer().render(out, node); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// renderer returns a Renderer that shares the Markdown renderer's
+// traversal and writes plain text. Escaping is disabled, so the Markdown
+// renderers reused for nodes such as mentions and emoji write text as it
+// is.
+func (p *PlainTextRenderer) renderer() *Renderer {
+	options := p.options.RenderOptions
+	options.DisableEscaping = true
+	return &Renderer{options: options, syntax: p.syntax()}
+}
+
+// syntax returns the built-in rendering of plain text in the configured
+// styles
+func (p *PlainTextRenderer) syntax() *syntax {
+	plain := delimiters("", "")
+	return &syntax{
+		nodes: map[string]func(r *Renderer, w *markdownWriter, node *Node){
+			"paragraph":     p.renderParagraph,
+			"heading":       p.renderParagraph,
+			"bulletList":    p.renderList,
+			"orderedList":   p.renderList,
+			"taskList":      p.renderList,
+			"decisionList":  p.renderList,
+			"listItem":      p.renderListItem,
+			"taskItem":      p.renderListItem,
+			"decisionItem":  p.renderListItem,
+			"codeBlock":     p.renderCodeBlock,
+			"rule":          p.renderRule,
+			"blockquote":    p.renderBlockquote,
+			"hardBreak":     p.renderHardBreak,
+			"panel":         p.renderPanel,
+			"date":          p.renderDate,
+			"status":        p.renderStatus,
+			"media":         p.renderMedia,
+			"mediaGroup":    p.renderMediaGroup,
+			"mediaInline":   p.renderMedia,
+			"caption":       p.renderParagraph,
+			"inlineCard":    p.renderCard,
+			"blockCard":     p.renderCard,
+			"embedCard":     p.renderCard,
+			"expand":        p.renderExpand,
+			"nestedExpand":  p.renderExpand,
+			"layoutSection": p.renderLayout,
+			"table":         p.renderTable,
+		},
+		marks: map[string]MarkRenderer{
+			"strong":          plain,
+			"em":              plain,
+			"strike":          plain,
+			"underline":       plain,
+			"code":            plain,
+			"textColor":       plain,
+			"backgroundColor": plain,
+			"subsup":          plain,
+			"link": MarkRendererFunc(func(ctx *RenderContext, mark *Mark, text string) (string, string) {
+				href, _ := mark.Attrs["href"].(string)
+				if href == "" {
+					ctx.Warn(WarningMissingAttr, "link has no href")
+					return "", ""
+				}
+				return "", p.linkSuffix(text, href)
+			}),
+		},
+		escape:           func(text string) string { return text },
+		escapeLineStarts: func(text string, atLineStart bool) string { return text },
+		placeholder:      func(text string) string { return "[" + text + "]" },
+	}
+}
+
+// linkSuffix returns what follows the text of a link to url in the
+// configured LinkStyle. Nothing is added if the text already is the URL.
+func (p *PlainTextRenderer) linkSuffix(text, url string) string {
+	if p.options.LinkStyle == LinkStyleTextOnly || url == "" || text == url || "mailto:"+text == url {
+		return ""
+	}
+	return " (" + url + ")"
+}
+
+// renderParagraph renders a paragraph, heading or caption as a block of
+// text, wrapped to the configured width
+func (p *PlainTextRenderer) renderParagraph(r *Renderer, w *markdownWriter, node *Node) {
+	text := r.renderInline(w, node.Content)
+	if text == "" {
+		return
+	}
+	w.WriteString(p.wrap(w, text))
+	w.ensureNewlines(2)
+}
+
+// wrap breaks text into lines that fit the configured width once the
+// writer's indentation is added. Words longer than a line are kept whole.
+func (p *PlainTextRenderer) wrap(w *markdownWriter, text string) string {
+	if p.options.Width <= 0 {
+		return text
+	}
+	width := max(p.options.Width-w.prefixWidth(), 1)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		var wrapped strings.Builder
+		length := 0
+		for _, word := range strings.Fields(line) {
+			wordLength := utf8.RuneCountInString(word)
+			switch {
+			case length == 0:
+			case length+1+wordLength > width:
+				wrapped.WriteString("\n")
+				length = 0
+			default:
+				wrapped.WriteString(" ")
+				length++
+			}
+			wrapped.WriteString(word)
+			length += wordLength
+		}
+		lines[i] = wrapped.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderList renders a bulleted, numbered, task or decision list. Items
+// are indented by the width of their marker, so wrapped lines line up with
+// the text of the item.
+func (p *PlainTextRenderer) renderList(r *Renderer, w *markdownWriter, node *Node) {
+	start := 1
+	if order, ok := intAttr(node.Attrs, "order"); ok {
+		start = order
+	}
+
+	// Items of the list's own type are lists nested directly inside it
+	number := start
+	for i := range node.Content {
+		item := &node.Content[i]
+		w.ensureNewlines(1)
+
+		if item.Type == node.Type {
+			w.pushPrefix("  ", "  ")
+			r.renderNode(w, item)
+			w.popPrefix()
+			continue
+		}
+
+		marker := p.listMarker(w.state, node.Type, item, number)
+		number++
+
+		w.state.listItems = append(w.state.listItems, marker)
+		w.pushPrefix(marker+" ", strings.Repeat(" ", utf8.RuneCountInString(marker)+1))
+		r.renderNode(w, item)
+		w.popPrefix()
+		w.state.listItems = w.state.listItems[:len(w.state.listItems)-1]
+	}
+
+	if len(w.state.listItems) == 0 {
+		w.ensureNewlines(2)
+	} else {
+		w.ensureNewlines(1)
+	}
+}
+
+// listMarker returns the bullet or number of an item in a list of the
+// given type. Task and decision items are marked with their state.
+func (p *PlainTextRenderer) listMarker(s *renderState, listType string, item *Node, number int) string {
+	state, _ := item.Attrs["state"].(string)
+	switch {
+	case item.Type == "taskItem" && state == "DONE":
+		return "[x]"
+	case item.Type == "taskItem":
+		return "[ ]"
+	case item.Type == "decisionItem" && state == "DECIDED":
+		return "✓"
+	case item.Type == "decisionItem":
+		return "?"
+	case listType == "orderedList" && p.options.Numbering != NumberingNone:
+		marker := strconv.Itoa(number) + "."
+		if p.options.Numbering == NumberingOutline && len(s.listItems) > 0 {
+			if parent := s.listItems[len(s.listItems)-1]; parent != "" && parent[0] >= '0' && parent[0] <= '9' {
+				marker = parent + marker
+			}
+		}
+		return marker
+	}
+
+	bullets := p.options.Bullets
+	if len(bullets) == 0 {
+		bullets = DefaultBullets
+	}
+	return bullets[len(s.listItems)%len(bullets)]
+}
+
+// renderListItem renders the content of a list, task or decision item
+func (p *PlainTextRenderer) renderListItem(r *Renderer, w *markdownWriter, node *Node) {
+	// Task and decision items hold inline content
+	if node.Type != "listItem" {
+		w.WriteString(p.wrap(w, r.renderInline(w, node.Content)))
+		w.startLine()
+		return
+	}
+
+	if len(node.Content) == 0 {
+		w.startLine()
+		return
+	}
+	for i := range node.Content {
+		if i > 0 {
+			w.trimNewlines(1)
+			w.ensureNewlines(1)
+		}
+		r.renderNode(w, &node.Content[i])
+	}
+	w.trimNewlines(0)
+}
+
+// renderCodeBlock renders a code block indented by four spaces, without
+// wrapping its lines
+func (p *PlainTextRenderer) renderCodeBlock(r *Renderer, w *markdownWriter, node *Node) {
+	var code strings.Builder
+	for _, child := range node.Content {
+		code.WriteString(child.Text)
+	}
+
+	w.pushPrefix("    ", "    ")
+	w.WriteString(strings.TrimRight(code.String(), "\n"))
+	w.popPrefix()
+	w.ensureNewlines(2)
+}
+
+// renderRule renders a horizontal rule as a line of dashes
+func (p *PlainTextRenderer) renderRule(r *Renderer, w *markdownWriter, node *Node) {
+	w.WriteString("----------")
+	w.ensureNewlines(2)
+}
+
+// renderBlockquote renders a blockquote with "> " before each line, as
+// quotes are written in plain text email
+func (p *PlainTextRenderer) renderBlockquote(r *Renderer, w *markdownWriter, node *Node) {
+	w.pushPrefix("> ", "> ")
+	r.renderContent(w, node.Content)
+	w.popPrefix()
+	w.ensureNewlines(2)
+}
+
+// renderHardBreak renders a line break
+func (p *PlainTextRenderer) renderHardBreak(r *Renderer, w *markdownWriter, node *Node) {
+	w.WriteString("\n")
+}
+
+// renderPanel renders a panel's content after a line naming its type, such
+// as "Warning:", or the icon of a custom panel
+func (p *PlainTextRenderer) renderPanel(r *Renderer, w *markdownWriter, node *Node) {
+	panelType, _ := node.Attrs["panelType"].(string)
+
+	label := panelIcon(node)
+	if panelType != "custom" && panelType != "" {
+		label = strings.ToUpper(panelType[:1]) + panelType[1:] + ":"
+	}
+	if label != "" {
+		w.WriteString(label)
+		w.ensureNewlines(1)
+	}
+
+	r.renderContent(w, node.Content)
+	w.ensureNewlines(2)
+}
+
+// renderDate renders a date as text in the configured layout, or as an
+// ISO 8601 timestamp with DateStyleISO
+func (p *PlainTextRenderer) renderDate(r *Renderer, w *markdownWriter, node *Node) {
+	t, text, ok := r.options.formatDate(w.state, node)
+	if ok && r.options.DateStyle == DateStyleISO {
+		text = t.Format(time.RFC3339)
+	}
+	w.WriteString(text)
+}
+
+// renderStatus renders a status lozenge as its text in brackets, or after a
+// colored circle with StatusStyleEmoji
+func (p *PlainTextRenderer) renderStatus(r *Renderer, w *markdownWriter, node *Node) {
+	text, ok := node.Attrs["text"].(string)
+	if !ok {
+		w.state.warn(WarningMissingAttr, "status has no text")
+		text = "STATUS"
+	}
+
+	if r.options.StatusStyle == StatusStyleEmoji {
+		color, _ := node.Attrs["color"].(string)
+		colors, ok := statusColors[color]
+		if !ok {
+			colors = statusColors["neutral"]
+		}
+		w.WriteString(colors.emoji + " " + text)
+		return
+	}
+	w.WriteString("[" + text + "]")
+}
+
+// renderMedia renders an image or attachment as its name, followed by its
+// URL in the configured LinkStyle
+func (p *PlainTextRenderer) renderMedia(r *Renderer, w *markdownWriter, node *Node) {
+	name, _ := node.Attrs["alt"].(string)
+	label := "Image"
+	if node.Type == "mediaInline" {
+		label = "Attachment"
+		if name == "" {
+			name, _ = node.Attrs["id"].(string)
+		}
+	}
+	if name != "" {
+		label += ": " + name
+	}
+
+	url, ok := r.options.mediaURL(node)
+	if !ok {
+		w.state.warn(WarningUnresolvedMedia, node.Type+" has no URL or id to link to")
+	}
+	w.WriteString("[" + label + "]" + p.linkSuffix("", url))
+}
+
+// renderMediaGroup renders a mediaGroup node as a list of attachments
+func (p *PlainTextRenderer) renderMediaGroup(r *Renderer, w *markdownWriter, node *Node) {
+	bullet := p.listMarker(w.state, "bulletList", node, 0)
+	for i := range node.Content {
+		media := &node.Content[i]
+		if media.Type != "media" {
+			continue
+		}
+
+		w.ensureNewlines(1)
+		w.pushPrefix(bullet+" ", strings.Repeat(" ", utf8.RuneCountInString(bullet)+1))
+		w.state.enter(media)
+		name, _ := media.Attrs["alt"].(string)
+		if name == "" {
+			name, _ = media.Attrs["id"].(string)
+		}
+		url, ok := r.options.mediaURL(media)
+		if !ok {
+			w.state.warn(WarningUnresolvedMedia, "media has no URL or id to link to")
+		}
+		w.WriteString(name + p.linkSuffix(name, url))
+		w.state.leave()
+		w.popPrefix()
+	}
+	w.ensureNewlines(2)
+}
+
+// renderCard renders a smart link as the linked page's name followed by
+// its URL, or as the URL alone if the name isn't known
+func (p *PlainTextRenderer) renderCard(r *Renderer, w *markdownWriter, node *Node) {
+	url, name, _ := cardDetails(node)
+	if url == "" {
+		w.state.warn(WarningMissingAttr, node.Type+" has no url")
+		return
+	}
+
+	if name == "" {
+		name = url
+	}
+	w.WriteString(name + p.linkSuffix(name, url))
+
+	if node.Type != "inlineCard" {
+		w.ensureNewlines(2)
+	}
+}
+
+// renderExpand renders an expand as its title followed by its content
+func (p *PlainTextRenderer) renderExpand(r *Renderer, w *markdownWriter, node *Node) {
+	if title, _ := node.Attrs["title"].(string); title != "" {
+		w.WriteString(p.wrap(w, title))
+		w.ensureNewlines(2)
+	}
+	r.renderContent(w, node.Content)
+	w.ensureNewlines(2)
+}
+
+// renderLayout renders the columns of a layout one after another
+func (p *PlainTextRenderer) renderLayout(r *Renderer, w *markdownWriter, node *Node) {
+	r.renderSequentialLayout(w, layoutColumns(node))
+}
+
+// renderTable renders a table as columns of text aligned with spaces, with
+// a line of dashes under a header row. Each cell is written on one line.
+func (p *PlainTextRenderer) renderTable(r *Renderer, w *markdownWriter, node *Node) {
+	rows := tableRows(node)
+
+	var cells [][]string
+	var widths []int
+	for _, row := range rows {
+		w.state.enter(row)
+		var texts []string
+		for i := range row.Content {
+			cell := &row.Content[i]
+			w.state.enter(cell)
+			if isMergedCell(cell) {
+				w.state.warn(WarningLossy, "merged cell written as a single cell")
+			}
+			text := strings.Join(strings.Fields(r.renderBlocks(w, cell.Content)), " ")
+			w.state.leave()
+
+			texts = append(texts, text)
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(text))
+		}
+		w.state.leave()
+		cells = append(cells, texts)
+	}
+
+	for i, row := range rows {
+		w.ensureNewlines(1)
+		w.WriteString(tableLine(cells[i], widths))
+
+		if i == 0 && isHeaderRow(row) {
+			dashes := make([]string, len(cells[i]))
+			for j := range dashes {
+				dashes[j] = strings.Repeat("-", widths[j])
+			}
+			w.ensureNewlines(1)
+			w.WriteString(tableLine(dashes, widths))
+		}
+	}
+	w.ensureNewlines(2)
+}
+
+// tableLine pads cells to their column widths and joins them with two
+// spaces
+func tableLine(cells []string, widths []int) string {
+	var line strings.Builder
+	for i, cell := range cells {
+		if i > 0 {
+			line.WriteString("  ")
+		}
+		line.WriteString(cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+	}
+	return strings.TrimRight(line.String(), " ")
+}
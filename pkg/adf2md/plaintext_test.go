@@ -0,0 +1,126 @@
+package adf2md_test
+
+import (
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestRenderPlainText(t *testing.T) {
+	input := `{"version":1,"type":"doc","content":[
+		{"type":"heading","attrs":{"level":1},"content":[{"type":"text","text":"Release notes"}]},
+		{"type":"paragraph","content":[
+			{"type":"text","text":"Read "},
+			{"type":"text","text":"the docs","marks":[{"type":"link","attrs":{"href":"https://example.com/docs"}},{"type":"strong"}]},
+			{"type":"text","text":" before upgrading, "},
+			{"type":"mention","attrs":{"id":"abc","text":"@Jane"}},
+			{"type":"text","text":" "},
+			{"type":"emoji","attrs":{"shortName":":tada:","text":"🎉"}}
+		]},
+		{"type":"orderedList","content":[
+			{"type":"listItem","content":[
+				{"type":"paragraph","content":[{"type":"text","text":"Back up"}]},
+				{"type":"orderedList","content":[
+					{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"Database"}]}]}
+				]}
+			]},
+			{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"Upgrade"}]}]}
+		]},
+		{"type":"taskList","attrs":{"localId":"l"},"content":[
+			{"type":"taskItem","attrs":{"localId":"a","state":"DONE"},"content":[{"type":"text","text":"Announce"}]}
+		]},
+		{"type":"paragraph","content":[
+			{"type":"status","attrs":{"text":"DONE","color":"green"}},
+			{"type":"text","text":" on "},
+			{"type":"date","attrs":{"timestamp":"1700000000000"}}
+		]}
+	]}`
+
+	tests := []struct {
+		name     string
+		options  adf2md.PlainTextOptions
+		expected string
+	}{
+		{
+			name:     "Defaults",
+			expected: "Release notes\n\nRead the docs (https://example.com/docs) before upgrading, @Jane 🎉\n\n1. Back up\n   1. Database\n2. Upgrade\n\n[x] Announce\n\n[DONE] on 2023-11-14\n\n",
+		},
+		{
+			name: "Outline numbering and text links",
+			options: adf2md.PlainTextOptions{
+				Numbering: adf2md.NumberingOutline,
+				LinkStyle: adf2md.LinkStyleTextOnly,
+			},
+			expected: "Release notes\n\nRead the docs before upgrading, @Jane 🎉\n\n1. Back up\n   1.1. Database\n2. Upgrade\n\n[x] Announce\n\n[DONE] on 2023-11-14\n\n",
+		},
+		{
+			name: "Bullets and shared styles",
+			options: adf2md.PlainTextOptions{
+				RenderOptions: adf2md.RenderOptions{
+					StatusStyle: adf2md.StatusStyleEmoji,
+					DateLayout:  "Jan 2, 2006",
+				},
+				Numbering: adf2md.NumberingNone,
+				Bullets:   []string{"-", "+"},
+				LinkStyle: adf2md.LinkStyleTextOnly,
+			},
+			expected: "Release notes\n\nRead the docs before upgrading, @Jane 🎉\n\n- Back up\n  + Database\n- Upgrade\n\n[x] Announce\n\n🟢 DONE on Nov 14, 2023\n\n",
+		},
+		{
+			name:     "Wrapping",
+			options:  adf2md.PlainTextOptions{Width: 20, LinkStyle: adf2md.LinkStyleTextOnly},
+			expected: "Release notes\n\nRead the docs before\nupgrading, @Jane 🎉\n\n1. Back up\n   1. Database\n2. Upgrade\n\n[x] Announce\n\n[DONE] on 2023-11-14\n\n",
+		},
+	}
+
+	node, err := adf2md.ParseADF(input)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := adf2md.NewPlainTextRenderer().WithOptions(tt.options).RenderToText(node)
+			if err != nil {
+				t.Fatalf("RenderToText failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRenderPlainTextTable(t *testing.T) {
+	input := `{"version":1,"type":"doc","content":[
+		{"type":"table","content":[
+			{"type":"tableRow","content":[
+				{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Service"}]}]},
+				{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Owner"}]}]}
+			]},
+			{"type":"tableRow","content":[
+				{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"api"}]}]},
+				{"type":"tableCell","content":[
+					{"type":"paragraph","content":[{"type":"text","text":"Platform"}]},
+					{"type":"paragraph","content":[{"type":"text","text":"team"}]}
+				]}
+			]}
+		]}
+	]}`
+	expected := "Service  Owner\n-------  -------------\napi      Platform team\n\n"
+
+	node, err := adf2md.ParseADF(input)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	result, err := adf2md.NewPlainTextRenderer().RenderToText(node)
+	if err != nil {
+		t.Fatalf("RenderToText failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("\nExpected: %q\nGot:      %q", expected, result)
+	}
+}
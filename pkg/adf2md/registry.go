@@ -0,0 +1,199 @@
+package adf2md
+
+// NodeRenderer renders ADF nodes of one type. Implementations are
+// registered on a Renderer with Register and replace the built-in
+// rendering for that type.
+type NodeRenderer interface {
+	// RenderNode writes the output for node through ctx. Returning an
+	// error stops rendering and is returned to the caller.
+	RenderNode(ctx *RenderContext, node *Node) error
+}
+
+// NodeRendererFunc adapts an ordinary function to the NodeRenderer interface
+type NodeRendererFunc func(ctx *RenderContext, node *Node) error
+
+// RenderNode calls f(ctx, node)
+func (f NodeRendererFunc) RenderNode(ctx *RenderContext, node *Node) error {
+	return f(ctx, node)
+}
+
+// DefaultNodeRenderer is a NodeRenderer that renders any node with the
+// built-in renderer for its type, for custom renderers to wrap or fall
+// back to
+var DefaultNodeRenderer NodeRenderer = NodeRendererFunc(func(ctx *RenderContext, node *Node) error {
+	return ctx.RenderDefault(node)
+})
+
+// Register sets a custom renderer for a node type, replacing the built-in
+// one. Registering nil restores the built-in renderer.
+func (r *Renderer) Register(nodeType string, nodeRenderer NodeRenderer) *Renderer {
+	if nodeRenderer == nil {
+		delete(r.nodeRenderers, nodeType)
+		return r
+	}
+
+	if r.nodeRenderers == nil {
+		r.nodeRenderers = make(map[string]NodeRenderer)
+	}
+	r.nodeRenderers[nodeType] = nodeRenderer
+	return r
+}
+
+// MarkRenderer renders marks of one type, such as strong or link, by
+// returning the delimiters to write before and after the marked text.
+// Implementations are registered on a Renderer with RegisterMark.
+type MarkRenderer interface {
+	// RenderMark returns the opening and closing delimiters for mark around
+	// text, which is the content they will wrap with any inner marks
+	// already applied. Returning two empty strings drops the mark.
+	RenderMark(ctx *RenderContext, mark *Mark, text string) (open, close string)
+}
+
+// MarkRendererFunc adapts an ordinary function to the MarkRenderer interface
+type MarkRendererFunc func(ctx *RenderContext, mark *Mark, text string) (string, string)
+
+// RenderMark calls f(ctx, mark, text)
+func (f MarkRendererFunc) RenderMark(ctx *RenderContext, mark *Mark, text string) (string, string) {
+	return f(ctx, mark, text)
+}
+
+// PlainMarkRenderer is a MarkRenderer that drops the mark, leaving its text
+// unformatted
+var PlainMarkRenderer MarkRenderer = delimiters("", "")
+
+// DefaultMarkRenderer is a MarkRenderer that returns the built-in
+// delimiters for any mark, for custom renderers to wrap or fall back to
+var DefaultMarkRenderer MarkRenderer = MarkRendererFunc(func(ctx *RenderContext, mark *Mark, text string) (string, string) {
+	if builtin, ok := ctx.renderer.builtinMark(mark.Type); ok {
+		return builtin.RenderMark(ctx, mark, text)
+	}
+	return "", ""
+})
+
+// RegisterMark sets a custom renderer for a mark type, replacing the
+// built-in one. Registering nil restores the built-in renderer.
+//
+// Whatever renderers are registered, links are always applied outside of
+// other marks and code inside of them, so the output nests correctly.
+func (r *Renderer) RegisterMark(markType string, markRenderer MarkRenderer) *Renderer {
+	if markRenderer == nil {
+		delete(r.markRenderers, markType)
+		return r
+	}
+
+	if r.markRenderers == nil {
+		r.markRenderers = make(map[string]MarkRenderer)
+	}
+	r.markRenderers[markType] = markRenderer
+	return r
+}
+
+// RenderContext is passed to a NodeRenderer or MarkRenderer and gives access to the output
+// and to the rest of the rendering machinery
+type RenderContext struct {
+	renderer *Renderer
+	w        *markdownWriter
+}
+
+// context returns the RenderContext for rendering to w
+func (r *Renderer) context(w *markdownWriter) *RenderContext {
+	if w.context == nil || w.context.renderer != r {
+		w.context = &RenderContext{renderer: r, w: w}
+	}
+	return w.context
+}
+
+// Options returns the options of the Renderer doing the rendering
+func (c *RenderContext) Options() RenderOptions {
+	return c.renderer.options
+}
+
+// WriteString writes content to the output. Lines are prefixed with the
+// indentation or quote markers of any enclosing blocks.
+func (c *RenderContext) WriteString(s string) {
+	c.w.WriteString(s)
+}
+
+// EnsureNewlines makes sure the next content is preceded by at least n
+// newlines: 1 starts a new line and 2 leaves a blank line, as between
+// blocks. Newlines are held back until more content arrives, so an
+// enclosing block can still tighten the spacing.
+func (c *RenderContext) EnsureNewlines(n int) {
+	c.w.ensureNewlines(n)
+}
+
+// PushPrefix starts a block whose first line begins with first and whose
+// following lines begin with rest, e.g. a list marker and its indentation
+func (c *RenderContext) PushPrefix(first, rest string) {
+	c.w.pushPrefix(first, rest)
+}
+
+// PopPrefix ends the block started by the last PushPrefix
+func (c *RenderContext) PopPrefix() {
+	c.w.popPrefix()
+}
+
+// AtLineStart reports whether the next content will start a new line
+func (c *RenderContext) AtLineStart() bool {
+	return c.w.atLineStart()
+}
+
+// EscapeText escapes characters in text that would be read as Markdown
+// syntax (or the syntax of the output format), unless escaping is disabled
+// in the options
+func (c *RenderContext) EscapeText(text string) string {
+	if c.renderer.options.DisableEscaping {
+		return text
+	}
+	return c.renderer.escapeText(text, escapeInline)
+}
+
+// Path returns the location of the node being rendered, as a JSON pointer
+// such as "/content/2/content/0"
+func (c *RenderContext) Path() string {
+	return c.w.state.path()
+}
+
+// Warn records a warning about the node being rendered, to be returned in
+// the RenderResult
+func (c *RenderContext) Warn(kind WarningKind, message string) {
+	c.w.state.warn(kind, message)
+}
+
+// RenderNode renders a node, using any custom renderer registered for its type
+func (c *RenderContext) RenderNode(node *Node) error {
+	c.renderer.renderNode(c.w, node)
+	return c.w.err
+}
+
+// RenderChildren renders the content of a node, using any custom renderers
+// registered for the children's types
+func (c *RenderContext) RenderChildren(node *Node) error {
+	c.renderer.renderContent(c.w, node.Content)
+	return c.w.err
+}
+
+// RenderDefault renders a node with the built-in renderer for its type,
+// skipping any custom renderer registered for it. Children are still
+// rendered with their custom renderers.
+func (c *RenderContext) RenderDefault(node *Node) error {
+	if node == nil || c.w.err != nil {
+		return c.w.err
+	}
+
+	// A custom renderer may hand over a node other than its own
+	if c.w.state.current() != node {
+		c.w.state.enter(node)
+		defer c.w.state.leave()
+	}
+
+	c.renderer.renderDefault(c.w, node)
+	return c.w.err
+}
+
+// RenderInline renders inline nodes to a string instead of the output, for
+// renderers that need to inspect or rearrange their content
+func (c *RenderContext) RenderInline(nodes []Node) (string, error) {
+	content := c.renderer.renderInline(c.w, nodes)
+	return content, c.w.err
+}
@@ -0,0 +1,164 @@
+package adf2md_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestRegister(t *testing.T) {
+	mention := adf2md.NodeRendererFunc(func(ctx *adf2md.RenderContext, node *adf2md.Node) error {
+		id, _ := node.Attrs["id"].(string)
+		ctx.WriteString("<@" + id + ">")
+		return nil
+	})
+
+	// Wraps the built-in panel renderer in a custom heading
+	panel := adf2md.NodeRendererFunc(func(ctx *adf2md.RenderContext, node *adf2md.Node) error {
+		ctx.WriteString("Note:")
+		ctx.EnsureNewlines(2)
+		return adf2md.DefaultNodeRenderer.RenderNode(ctx, node)
+	})
+
+	// Renders children through the default machinery inside its own prefix
+	quote := adf2md.NodeRendererFunc(func(ctx *adf2md.RenderContext, node *adf2md.Node) error {
+		ctx.PushPrefix("| ", "| ")
+		err := ctx.RenderChildren(node)
+		ctx.PopPrefix()
+		ctx.EnsureNewlines(2)
+		return err
+	})
+
+	// Writes list items with its own marker
+	item := adf2md.NodeRendererFunc(func(ctx *adf2md.RenderContext, node *adf2md.Node) error {
+		ctx.PushPrefix("+ ", "  ")
+		err := ctx.RenderChildren(node)
+		ctx.PopPrefix()
+		return err
+	})
+
+	// Wraps the built-in cell renderer, which depends on the table around it
+	cell := adf2md.NodeRendererFunc(func(ctx *adf2md.RenderContext, node *adf2md.Node) error {
+		ctx.WriteString("~")
+		err := ctx.RenderDefault(node)
+		ctx.WriteString("~")
+		return err
+	})
+
+	tests := []struct {
+		name     string
+		nodeType string
+		renderer adf2md.NodeRenderer
+		input    string
+		expected string
+	}{
+		{
+			name:     "Custom inline renderer",
+			nodeType: "mention",
+			renderer: mention,
+			input:    `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"Hi "},{"type":"mention","attrs":{"id":"abc","text":"Jane"}}]}]}`,
+			expected: "Hi <@abc>\n\n",
+		},
+		{
+			name:     "Custom renderer used for nested nodes",
+			nodeType: "mention",
+			renderer: mention,
+			input:    `{"version":1,"type":"doc","content":[{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"mention","attrs":{"id":"abc"}}]}]}]}]}`,
+			expected: "* <@abc>\n",
+		},
+		{
+			name:     "Wrapping the built-in renderer",
+			nodeType: "panel",
+			renderer: panel,
+			input:    `{"version":1,"type":"doc","content":[{"type":"panel","attrs":{"panelType":"info"},"content":[{"type":"paragraph","content":[{"type":"text","text":"Body"}]}]}]}`,
+			expected: "Note:\n\n> **Panel (info)**\n> Body\n\n",
+		},
+		{
+			name:     "Rendering children with a prefix",
+			nodeType: "blockquote",
+			renderer: quote,
+			input:    `{"version":1,"type":"doc","content":[{"type":"blockquote","content":[{"type":"paragraph","content":[{"type":"text","text":"One"}]},{"type":"paragraph","content":[{"type":"text","text":"Two"}]}]}]}`,
+			expected: "| One\n|\n| Two\n\n",
+		},
+		{
+			name:     "Custom list item renderer",
+			nodeType: "listItem",
+			renderer: item,
+			input:    `{"version":1,"type":"doc","content":[{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"One"}]}]},{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"Two"}]}]}]}]}`,
+			expected: "+ One\n\n+ Two\n\n",
+		},
+		{
+			name:     "Custom task item renderer",
+			nodeType: "taskItem",
+			renderer: item,
+			input:    `{"version":1,"type":"doc","content":[{"type":"taskList","attrs":{"localId":"l"},"content":[{"type":"taskItem","attrs":{"localId":"a","state":"TODO"},"content":[{"type":"text","text":"Ship it"}]}]}]}`,
+			expected: "+ Ship it\n",
+		},
+		{
+			name:     "Custom table cell renderer in a pipe table",
+			nodeType: "tableCell",
+			renderer: cell,
+			input:    `{"version":1,"type":"doc","content":[{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Name"}]}]}]},{"type":"tableRow","content":[{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"a"}]}]}]}]}]}`,
+			expected: "| Name |\n| --- |\n| ~a~ |\n\n",
+		},
+		{
+			name:     "Custom table cell renderer in an HTML table",
+			nodeType: "tableCell",
+			renderer: cell,
+			input:    `{"version":1,"type":"doc","content":[{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableCell","attrs":{"colspan":2},"content":[{"type":"paragraph","content":[{"type":"text","text":"a"}]}]}]}]}]}`,
+			expected: "<table>\n<tr>\n~<td colspan=\"2\">\n\na\n\n</td>~\n</tr>\n</table>\n\n",
+		},
+		{
+			name:     "Custom layout column renderer",
+			nodeType: "layoutColumn",
+			renderer: quote,
+			input:    `{"version":1,"type":"doc","content":[{"type":"layoutSection","content":[{"type":"layoutColumn","attrs":{"width":50},"content":[{"type":"paragraph","content":[{"type":"text","text":"Left"}]}]},{"type":"layoutColumn","attrs":{"width":50},"content":[{"type":"paragraph","content":[{"type":"text","text":"Right"}]}]}]}]}`,
+			expected: "| Left\n\n| Right\n\n",
+		},
+		{
+			name:     "Registering nil restores the built-in renderer",
+			nodeType: "mention",
+			renderer: nil,
+			input:    `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"mention","attrs":{"id":"abc","text":"Jane"}}]}]}`,
+			expected: "@Jane\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := adf2md.ParseADF(tt.input)
+			if err != nil {
+				t.Fatalf("Failed to parse ADF: %v", err)
+			}
+
+			renderer := adf2md.NewRenderer().Register("mention", mention).Register(tt.nodeType, tt.renderer)
+
+			result, err := renderer.RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRegisterError(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	node, err := adf2md.ParseADF(`{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"a"},{"type":"emoji","attrs":{"shortName":":smile:"}},{"type":"text","text":"b"}]}]}`)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	renderer := adf2md.NewRenderer().Register("emoji", adf2md.NodeRendererFunc(func(ctx *adf2md.RenderContext, node *adf2md.Node) error {
+		return errBoom
+	}))
+
+	if _, err := renderer.RenderToMarkdown(node); !errors.Is(err, errBoom) {
+		t.Errorf("RenderToMarkdown() error = %v, want %v", err, errBoom)
+	}
+}
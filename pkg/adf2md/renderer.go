@@ -1,21 +1,96 @@
 package adf2md
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
 // Renderer handles converting ADF nodes to Markdown
 type Renderer struct {
 	// Options for customizing the Markdown output
 	options RenderOptions
+
+	// Custom renderers registered for node types, used instead of the
+	// built-in ones
+	nodeRenderers map[string]NodeRenderer
+
+	// Custom renderers registered for mark types
+	markRenderers map[string]MarkRenderer
+
+	// Handlers registered for extensions, keyed by "extensionType/extensionKey"
+	extensionHandlers map[string]ExtensionHandler
+
+	// Built-in rendering of the output format, or nil for Markdown
+	syntax *syntax
 }
 
 // RenderOptions contains configuration for the Markdown rendering
 type RenderOptions struct {
 	// Number of spaces used for list item indentation
 	ListIndent int
+
+	// How tables are rendered (defaults to TableModeAuto)
+	TableMode TableMode
+
+	// How smart links are rendered (defaults to CardStyleLink)
+	CardStyle CardStyle
+
+	// How multi-column page layouts are rendered (defaults to
+	// LayoutModeSequential)
+	LayoutMode LayoutMode
+
+	// Written between the columns of a layout in LayoutModeSequential,
+	// such as "---". Nothing is written if it is empty.
+	LayoutSeparator string
+
+	// Looks up the users behind mentions, whose names often aren't stored
+	// in the document
+	MentionResolver MentionResolver
+
+	// Template for mentions, where {id}, {name}, {email} and {url} are
+	// replaced with the user's details, e.g. "[@{name}]({url})". {name}
+	// falls back to the account ID. Mentions are written as @name if empty.
+	MentionTemplate string
+
+	// How panels are rendered (defaults to PanelStyleLegacy)
+	PanelStyle PanelStyle
+
+	// How status lozenges are rendered (defaults to StatusStyleBrackets)
+	StatusStyle StatusStyle
+
+	// How dates are written (defaults to DateStylePlain)
+	DateStyle DateStyle
+
+	// Go time layout for dates, e.g. "Jan 2, 2006". Defaults to
+	// DefaultDateLayout, which gives dates such as 2024-11-14.
+	DateLayout string
+
+	// Time zone dates are shown in. Defaults to UTC.
+	DateLocation *time.Location
+
+	// Works out the URLs of attached files and images. Attachments link to
+	// /wiki/download/attachments/<collection>/<id> if it is nil, or are
+	// left unresolved if they have no collection.
+	MediaResolver MediaResolver
+
+	// Render expands as a bold title followed by their content instead of
+	// an HTML details block, for targets without HTML support
+	FlattenExpands bool
+
+	// Write text exactly as it appears in the document instead of escaping
+	// characters that would be read as Markdown syntax
+	DisableEscaping bool
+
+	// Fail with an *UnsupportedError listing every node and mark that
+	// can't be rendered. Otherwise unsupported nodes are written as
+	// placeholders and unsupported marks are dropped.
+	Strict bool
 }
 
 // NewRenderer creates a new Markdown renderer with default options
@@ -35,491 +110,571 @@ func (r *Renderer) WithOptions(options RenderOptions) *Renderer {
 
 // RenderToMarkdown converts an ADF node to Markdown
 func (r *Renderer) RenderToMarkdown(node *Node) (string, error) {
-	if node == nil {
-		return "", fmt.Errorf("nil node provided")
+	var result strings.Builder
+	if _, err := r.render(&result, node); err != nil {
+		return "", err
 	}
-	
-	result := r.renderNode(node)
-	return result, nil
+	return result.String(), nil
 }
 
-// renderNode processes a single ADF node and returns its Markdown representation
-func (r *Renderer) renderNode(node *Node) string {
+// RenderToResult converts an ADF node to Markdown, also returning warnings
+// about anything in the document that couldn't be converted faithfully
+func (r *Renderer) RenderToResult(node *Node) (*RenderResult, error) {
+	var result strings.Builder
+	warnings, err := r.render(&result, node)
+	if err != nil {
+		return nil, err
+	}
+	return &RenderResult{Markdown: result.String(), Warnings: warnings}, nil
+}
+
+// Render converts an ADF node to Markdown, writing the output to w as it
+// is produced rather than building it up in memory. In strict mode the
+// output is held back until the whole document has been rendered, and
+// nothing is written to w if it contains unsupported nodes or marks.
+func (r *Renderer) Render(w io.Writer, node *Node) error {
+	out := bufio.NewWriter(w)
+	if _, err := r.render(out, node); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// render writes the Markdown for a node to out and returns the warnings
+// found along the way
+func (r *Renderer) render(out io.Writer, node *Node) ([]Warning, error) {
 	if node == nil {
-		return ""
+		return nil, fmt.Errorf("nil node provided")
+	}
+
+	// Strict mode can only fail once the whole document has been seen, so
+	// the output is held back until then
+	var held bytes.Buffer
+	target := out
+	if r.options.Strict {
+		target = &held
+	}
+
+	w := newMarkdownWriter(target)
+	r.renderNode(w, node)
+	if err := w.finish(); err != nil {
+		return nil, err
+	}
+
+	if r.options.Strict {
+		if unsupported := unsupported(w.state.warnings); len(unsupported) > 0 {
+			return nil, &UnsupportedError{Unsupported: unsupported}
+		}
+		if _, err := held.WriteTo(out); err != nil {
+			return nil, err
+		}
+	}
+	return w.state.warnings, nil
+}
+
+// renderNode writes the Markdown representation of a single ADF node,
+// using a registered NodeRenderer if there is one for its type
+func (r *Renderer) renderNode(w *markdownWriter, node *Node) {
+	if node == nil || w.err != nil {
+		return
+	}
+
+	w.state.enter(node)
+	defer w.state.leave()
+
+	if custom, ok := r.nodeRenderers[node.Type]; ok {
+		if err := custom.RenderNode(r.context(w), node); err != nil {
+			w.fail(err)
+		}
+		return
+	}
+
+	r.renderDefault(w, node)
+}
+
+// renderDefault writes a node using the built-in renderer for its type
+func (r *Renderer) renderDefault(w *markdownWriter, node *Node) {
+	if r.syntax != nil {
+		if render, ok := r.syntax.nodes[node.Type]; ok {
+			render(r, w, node)
+			return
+		}
 	}
 
 	switch node.Type {
 	case "doc":
-		return r.renderContent(node.Content)
+		r.renderContent(w, node.Content)
 	case "paragraph":
-		return r.renderParagraph(node)
+		r.renderParagraph(w, node)
 	case "text":
-		return r.renderText(node)
+		r.renderText(w, node)
 	case "heading":
-		return r.renderHeading(node)
+		r.renderHeading(w, node)
 	case "bulletList":
-		return r.renderBulletList(node) + "\n"
+		r.renderBulletList(w, node)
 	case "orderedList":
-		return r.renderOrderedList(node) + "\n"
+		r.renderOrderedList(w, node)
 	case "listItem":
-		return r.renderListItem(node)
+		r.renderListItem(w, node)
 	case "taskList":
-		return r.renderTaskList(node) + "\n"
+		r.renderTaskList(w, node)
 	case "taskItem":
-		return r.renderTaskItem(node)
+		r.renderTaskItem(w, node)
 	case "decisionList":
-		return r.renderDecisionList(node) + "\n"
+		r.renderDecisionList(w, node)
 	case "decisionItem":
-		return r.renderDecisionItem(node)
+		r.renderDecisionItem(w, node)
 	case "codeBlock":
-		return r.renderCodeBlock(node)
+		r.renderCodeBlock(w, node)
 	case "rule":
-		return r.renderRule()
+		r.renderRule(w)
 	case "blockquote":
-		return r.renderBlockquote(node)
+		r.renderBlockquote(w, node)
 	case "hardBreak":
-		return r.renderHardBreak()
+		r.renderHardBreak(w)
 	case "panel":
-		return r.renderPanel(node)
+		r.renderPanel(w, node)
 	case "mention":
-		return r.renderMention(node)
+		r.renderMention(w, node)
 	case "emoji":
-		return r.renderEmoji(node)
+		r.renderEmoji(w, node)
 	case "date":
-		return r.renderDate(node)
+		r.renderDate(w, node)
 	case "status":
-		return r.renderStatus(node)
+		r.renderStatus(w, node)
 	case "mediaSingle":
-		return r.renderMediaSingle(node)
+		r.renderMediaSingle(w, node)
 	case "media":
-		return r.renderMedia(node)
+		r.renderMedia(w, node)
+	case "mediaGroup":
+		r.renderMediaGroup(w, node)
+	case "mediaInline":
+		r.renderMediaLink(w, node)
 	case "caption":
-		return r.renderCaption(node)
+		r.renderCaption(w, node)
+	case "inlineCard", "blockCard", "embedCard":
+		r.renderCard(w, node)
+	case "expand", "nestedExpand":
+		r.renderExpand(w, node)
+	case "layoutSection":
+		r.renderLayoutSection(w, node)
+	case "layoutColumn":
+		r.renderLayoutColumn(w, node)
+	case "extension", "inlineExtension", "bodiedExtension", "multiBodiedExtension":
+		r.renderExtension(w, node)
+	case "table":
+		r.renderTable(w, node)
+	case "tableRow":
+		r.renderTableRow(w, node)
+	case "tableHeader", "tableCell":
+		r.renderTableCell(w, node)
 	default:
-		return r.renderUnknown(node)
+		r.renderUnknown(w, node)
 	}
 }
 
 // renderContent processes an array of ADF nodes
-func (r *Renderer) renderContent(nodes []Node) string {
-	if len(nodes) == 0 {
-		return ""
+func (r *Renderer) renderContent(w *markdownWriter, nodes []Node) {
+	for i := range nodes {
+		r.renderNode(w, &nodes[i])
 	}
+}
 
+// renderInline renders inline content to a string, as it would appear
+// partway through a line. Errors are passed on to the parent writer w.
+func (r *Renderer) renderInline(w *markdownWriter, nodes []Node) string {
 	var result strings.Builder
-	for _, node := range nodes {
-		result.WriteString(r.renderNode(&node))
-	}
+	inline := w.subWriter(&result)
+	inline.lineStart = false
+	r.renderContent(inline, nodes)
+	w.fail(inline.err)
+	return result.String()
+}
+
+// renderBlocks renders block content to a string without the newlines
+// that would separate it from a following block. Errors are passed on to
+// the parent writer w.
+func (r *Renderer) renderBlocks(w *markdownWriter, nodes []Node) string {
+	var result strings.Builder
+	blocks := w.subWriter(&result)
+	r.renderContent(blocks, nodes)
+	w.fail(blocks.err)
+	return result.String()
+}
+
+// renderToString renders a node to a string instead of the output, as it
+// would appear at the start of a line. Errors are passed on to the parent
+// writer w.
+func (r *Renderer) renderToString(w *markdownWriter, node *Node) string {
+	var result strings.Builder
+	sub := w.subWriter(&result)
+	r.renderNode(sub, node)
+	w.fail(sub.err)
 	return result.String()
 }
 
 // renderParagraph renders a paragraph node
-func (r *Renderer) renderParagraph(node *Node) string {
-	content := r.renderContent(node.Content)
-	if content == "" {
-		return ""
+func (r *Renderer) renderParagraph(w *markdownWriter, node *Node) {
+	written := w.written
+	r.renderContent(w, node.Content)
+	if w.written != written {
+		w.ensureNewlines(2)
 	}
-	return content + "\n\n"
 }
 
 // renderText renders a text node with any marks applied
-func (r *Renderer) renderText(node *Node) string {
+func (r *Renderer) renderText(w *markdownWriter, node *Node) {
 	if node.Text == "" {
-		return ""
+		return
+	}
+
+	for i := range node.Marks {
+		if !r.supportsMark(node.Marks[i].Type) {
+			w.state.warnMark(WarningUnknownMark, &node.Marks[i], "unsupported mark "+strconv.Quote(node.Marks[i].Type)+" dropped")
+		}
 	}
-	
+
 	text := node.Text
-	
-	// Apply marks in reverse order (from most nested to least nested)
-	if len(node.Marks) > 0 {
-		for i := len(node.Marks) - 1; i >= 0; i-- {
-			mark := node.Marks[i]
-			switch mark.Type {
-			case "strong":
-				text = "**" + text + "**"
-			case "em":
-				text = "*" + text + "*"
-			case "code":
-				text = "`" + text + "`"
-			case "strike":
-				text = "~~" + text + "~~"
-			case "underline":
-				// Markdown doesn't natively support underline, could use HTML or just let it pass
-				text = text // Could use "_" + text + "_" but that's italics in most Markdown
-			case "link":
-				if href, ok := mark.Attrs["href"].(string); ok {
-					text = "[" + text + "](" + href + ")"
-				}
-			case "textColor":
-				// Markdown doesn't support text color, could use HTML
-				text = text
-			case "backgroundColor":
-				// Markdown doesn't support background color, could use HTML
-				text = text
+	escape := !r.options.DisableEscaping
+
+	// Code spans are literal, so only text outside of them gets escaped
+	if escape && (!hasMark(node, "code") || r.escapesCode()) {
+		ctx := escapeInline
+		if hasMark(node, "link") {
+			ctx = escapeLinkLabel
+		}
+		text = r.escapeText(text, ctx)
+	}
+
+	// Emphasis can't open or close next to whitespace, so whitespace at
+	// the edges of marked text is written outside of the delimiters. Code
+	// spans are literal and keep theirs.
+	content, leading, trailing := text, "", ""
+	if len(node.Marks) > 0 && !hasMark(node, "code") {
+		trimmed := strings.TrimLeftFunc(content, unicode.IsSpace)
+		leading, content = content[:len(content)-len(trimmed)], trimmed
+		trimmed = strings.TrimRightFunc(content, unicode.IsSpace)
+		content, trailing = trimmed, content[len(trimmed):]
+	}
+
+	// Apply marks from the innermost outwards
+	wrapped := false
+	if content != "" && len(node.Marks) > 0 {
+		ctx := r.context(w)
+		marks := orderMarks(node.Marks)
+		for i := len(marks) - 1; i >= 0; i-- {
+			open, close := r.markDelimiters(ctx, &marks[i], content)
+			if open == "" && close == "" {
+				continue
 			}
+			content = open + content + close
+			wrapped = true
+		}
+	}
+
+	if wrapped {
+		w.WriteString(leading)
+		w.WriteString(content)
+		w.WriteString(trailing)
+		return
+	}
+
+	// Text that isn't wrapped in any mark delimiters could start a line
+	if escape {
+		text = r.escapeLineStarts(text, w.atLineStart())
+	}
+	w.WriteString(text)
+}
+
+// hasMark reports whether a node has a mark of the given type
+func hasMark(node *Node, markType string) bool {
+	for _, mark := range node.Marks {
+		if mark.Type == markType {
+			return true
 		}
 	}
-	
-	return text
+	return false
 }
 
 // renderHeading renders a heading node
-func (r *Renderer) renderHeading(node *Node) string {
+func (r *Renderer) renderHeading(w *markdownWriter, node *Node) {
 	level := 1
 	if lvl, ok := node.Attrs["level"].(float64); ok {
 		level = int(lvl)
+	} else {
+		w.state.warn(WarningMissingAttr, "heading has no level, using 1")
 	}
-	
+
 	// Make sure level is between 1-6
 	if level < 1 {
 		level = 1
 	} else if level > 6 {
 		level = 6
 	}
-	
-	content := r.renderContent(node.Content)
-	return strings.Repeat("#", level) + " " + content + "\n\n"
+
+	content := r.renderInline(w, node.Content)
+	if !r.options.DisableEscaping {
+		content = escapeHeading(content)
+	}
+	w.WriteString(strings.Repeat("#", level) + " " + content)
+	w.ensureNewlines(2)
 }
 
 // renderBulletList renders a bullet list node
-func (r *Renderer) renderBulletList(node *Node) string {
-	if len(node.Content) == 0 {
-		return ""
-	}
-	
-	var result strings.Builder
-	for i, item := range node.Content {
-		if i > 0 {
-			result.WriteString("\n")
-		}
-		result.WriteString("* " + r.renderListItem(&item))
+func (r *Renderer) renderBulletList(w *markdownWriter, node *Node) {
+	for i := range node.Content {
+		w.ensureNewlines(1)
+		r.renderMarkedItem(w, &node.Content[i], "* ")
 	}
-	
-	return result.String()
+	w.ensureNewlines(1)
 }
 
 // renderOrderedList renders an ordered list node
-func (r *Renderer) renderOrderedList(node *Node) string {
-	if len(node.Content) == 0 {
-		return ""
-	}
-	
-	var result strings.Builder
+func (r *Renderer) renderOrderedList(w *markdownWriter, node *Node) {
 	startOrder := 1
 	if order, ok := node.Attrs["order"].(float64); ok {
 		startOrder = int(order)
 	}
-	
-	for i, item := range node.Content {
-		if i > 0 {
-			result.WriteString("\n")
-		}
+
+	for i := range node.Content {
+		w.ensureNewlines(1)
 		num := startOrder + i
-		result.WriteString(strconv.Itoa(num) + ". " + r.renderListItem(&item))
+		r.renderMarkedItem(w, &node.Content[i], strconv.Itoa(num)+". ")
 	}
-	
-	return result.String()
+	w.ensureNewlines(1)
 }
 
-// renderListItem renders a list item node
-func (r *Renderer) renderListItem(node *Node) string {
-	if len(node.Content) == 0 {
-		return "\n"
-	}
+// renderMarkedItem renders an item of a bullet or ordered list, which the
+// built-in listItem renderer starts with marker
+func (r *Renderer) renderMarkedItem(w *markdownWriter, node *Node, marker string) {
+	w.state.itemMarker = marker
+	r.renderNode(w, node)
+	w.state.itemMarker = ""
+}
 
-	// Process the first child (usually a paragraph)
-	firstChild := r.renderNode(&node.Content[0])
-	firstChild = strings.TrimSuffix(firstChild, "\n\n") // Remove paragraph spacing
-	
-	// Process any additional content (nested lists, paragraphs, etc.)
-	var result strings.Builder
-	result.WriteString(firstChild)
-	
-	if len(node.Content) > 1 {
-		indent := strings.Repeat(" ", r.options.ListIndent)
-		
-		for _, child := range node.Content[1:] {
-			childContent := r.renderNode(&child)
-			
-			// For nested lists, we keep their formatting
-			if child.Type == "bulletList" || child.Type == "orderedList" || child.Type == "taskList" {
-				// Indent nested list items
-				lines := strings.Split(childContent, "\n")
-				for i, line := range lines {
-					if line != "" {
-						lines[i] = indent + line
-					}
-				}
-				result.WriteString("\n" + strings.Join(lines, "\n"))
-			} else {
-				// For other block elements (paragraphs, etc.), indent them
-				childContent = strings.TrimSuffix(childContent, "\n\n")
-				lines := strings.Split(childContent, "\n")
-				for i, line := range lines {
-					if line != "" {
-						lines[i] = indent + line
-					}
-				}
-				result.WriteString("\n" + strings.Join(lines, "\n"))
-			}
-		}
+// renderListItem renders a list item node. The marker given by its list is
+// written at the start of the item's first line and following lines are
+// indented.
+func (r *Renderer) renderListItem(w *markdownWriter, node *Node) {
+	marker := w.state.itemMarker
+	if marker == "" {
+		marker = "* "
 	}
-	
-	return result.String()
-}
+	w.pushPrefix(marker, strings.Repeat(" ", r.options.ListIndent))
+	defer w.popPrefix()
 
-// renderTaskList renders a task list node
-func (r *Renderer) renderTaskList(node *Node) string {
 	if len(node.Content) == 0 {
-		return ""
+		w.startLine()
+		return
 	}
-	
-	var result strings.Builder
-	for i, item := range node.Content {
+
+	// The first child (usually a paragraph) follows the marker, and any
+	// additional content (nested lists, paragraphs, etc.) follows directly
+	// on the next line
+	for i := range node.Content {
 		if i > 0 {
-			result.WriteString("\n")
+			w.trimNewlines(1)
+			w.ensureNewlines(1)
 		}
-		result.WriteString("- " + r.renderTaskItem(&item))
+		r.renderNode(w, &node.Content[i])
 	}
-	
-	return result.String()
+	w.trimNewlines(0)
+}
+
+// renderTaskList renders a task list node
+func (r *Renderer) renderTaskList(w *markdownWriter, node *Node) {
+	r.renderItemList(w, node)
 }
 
 // renderTaskItem renders a task item node
-func (r *Renderer) renderTaskItem(node *Node) string {
+func (r *Renderer) renderTaskItem(w *markdownWriter, node *Node) {
 	state, _ := node.Attrs["state"].(string)
 	checkbox := "[ ]"
 	if state == "DONE" {
 		checkbox = "[x]"
 	}
-	
-	content := r.renderContent(node.Content)
-	content = strings.TrimSuffix(content, "\n\n") // Remove paragraph spacing
-	
-	// Indent subsequent lines
-	lines := strings.Split(content, "\n")
-	for i := 1; i < len(lines); i++ {
-		if lines[i] != "" {
-			lines[i] = strings.Repeat(" ", r.options.ListIndent) + lines[i]
-		}
-	}
-	
-	return checkbox + " " + strings.Join(lines, "\n")
+
+	r.renderCheckItem(w, node, checkbox+" ")
 }
 
 // renderDecisionList renders a decision list node
-func (r *Renderer) renderDecisionList(node *Node) string {
-	if len(node.Content) == 0 {
-		return ""
-	}
-	
-	var result strings.Builder
-	for i, item := range node.Content {
-		if i > 0 {
-			result.WriteString("\n")
-		}
-		result.WriteString("- " + r.renderDecisionItem(&item))
-	}
-	
-	return result.String()
+func (r *Renderer) renderDecisionList(w *markdownWriter, node *Node) {
+	r.renderItemList(w, node)
 }
 
 // renderDecisionItem renders a decision item node
-func (r *Renderer) renderDecisionItem(node *Node) string {
+func (r *Renderer) renderDecisionItem(w *markdownWriter, node *Node) {
 	state, _ := node.Attrs["state"].(string)
 	prefix := "<D> "
 	if state != "DECIDED" {
 		prefix = "< > "
 	}
-	
-	content := r.renderContent(node.Content)
-	content = strings.TrimSuffix(content, "\n\n") // Remove paragraph spacing
-	
-	// Indent subsequent lines
-	lines := strings.Split(content, "\n")
-	for i := 1; i < len(lines); i++ {
-		if lines[i] != "" {
-			lines[i] = strings.Repeat(" ", r.options.ListIndent) + lines[i]
+
+	r.renderCheckItem(w, node, prefix)
+}
+
+// renderCheckItem renders a task or decision item as a "- " item starting
+// with its state
+func (r *Renderer) renderCheckItem(w *markdownWriter, node *Node, state string) {
+	w.pushPrefix("- ", strings.Repeat(" ", r.options.ListIndent))
+	defer w.popPrefix()
+
+	w.WriteString(state)
+	r.renderContent(w, node.Content)
+	w.trimNewlines(0)
+}
+
+// renderItemList renders a task or decision list. Lists nested directly
+// inside the list are indented under the previous item.
+func (r *Renderer) renderItemList(w *markdownWriter, node *Node) {
+	indent := strings.Repeat(" ", r.options.ListIndent)
+
+	for i := range node.Content {
+		item := &node.Content[i]
+		w.ensureNewlines(1)
+
+		if item.Type == node.Type {
+			w.pushPrefix(indent, indent)
+			r.renderNode(w, item)
+			w.trimNewlines(0)
+			w.popPrefix()
+			continue
 		}
+
+		r.renderNode(w, item)
 	}
-	
-	return prefix + strings.Join(lines, "\n")
+	w.ensureNewlines(1)
 }
 
 // renderCodeBlock renders a code block node
-func (r *Renderer) renderCodeBlock(node *Node) string {
+func (r *Renderer) renderCodeBlock(w *markdownWriter, node *Node) {
 	language := ""
 	if lang, ok := node.Attrs["language"].(string); ok {
 		language = lang
 	}
-	
+
 	var code string
 	if len(node.Content) > 0 {
 		code = node.Content[0].Text
 	}
-	
-	return "```" + language + "\n" + code + "\n```\n\n"
+
+	w.WriteString("```" + language + "\n" + code + "\n```")
+	w.ensureNewlines(2)
 }
 
 // renderRule renders a horizontal rule
-func (r *Renderer) renderRule() string {
-	return "---\n\n"
+func (r *Renderer) renderRule(w *markdownWriter) {
+	w.WriteString("---")
+	w.ensureNewlines(2)
 }
 
 // renderBlockquote renders a blockquote node
-func (r *Renderer) renderBlockquote(node *Node) string {
-	content := r.renderContent(node.Content)
-	
+func (r *Renderer) renderBlockquote(w *markdownWriter, node *Node) {
 	// Add blockquote prefix to each line
-	lines := strings.Split(content, "\n")
-	for i, line := range lines {
-		if line != "" {
-			lines[i] = "> " + line
-		}
-	}
-	
-	return strings.Join(lines, "\n") + "\n\n"
+	w.pushPrefix("> ", "> ")
+	r.renderContent(w, node.Content)
+	w.popPrefix()
+
+	w.ensureNewlines(2)
 }
 
 // renderHardBreak renders a hard break (line break)
-func (r *Renderer) renderHardBreak() string {
-	return "  \n"
+func (r *Renderer) renderHardBreak(w *markdownWriter) {
+	w.WriteString(hardBreak)
 }
 
-// renderPanel renders a panel node
-func (r *Renderer) renderPanel(node *Node) string {
-	panelType, _ := node.Attrs["panelType"].(string)
-	title := fmt.Sprintf("**Panel (%s)**", panelType)
-	
-	content := r.renderContent(node.Content)
-	if content != "" {
-		content = title + "\n" + content
-	} else {
-		content = title
-	}
-	
-	// Add blockquote prefix to each line
-	lines := strings.Split(content, "\n")
-	for i, line := range lines {
-		if line != "" {
-			lines[i] = "> " + line
-		}
-	}
-	
-	return strings.Join(lines, "\n") + "\n\n"
-}
+// hardBreak is the Markdown for a line break within a paragraph
+const hardBreak = "  \n"
 
 // renderMention renders a mention node
-func (r *Renderer) renderMention(node *Node) string {
-	text, _ := node.Attrs["text"].(string)
-	id, _ := node.Attrs["id"].(string)
-	
-	if text != "" {
-		return "@" + text
+func (r *Renderer) renderMention(w *markdownWriter, node *Node) {
+	user := r.options.mentionUser(node)
+	if user.Name == "" && user.ID == "" {
+		w.state.warn(WarningMissingAttr, "mention has no text or id")
 	}
-	return "@user:" + id
-}
 
-// renderEmoji renders an emoji node
-func (r *Renderer) renderEmoji(node *Node) string {
-	if text, ok := node.Attrs["text"].(string); ok {
-		return text
+	if r.options.MentionTemplate != "" {
+		w.WriteString(r.formatMention(user))
+		return
 	}
-	if shortName, ok := node.Attrs["shortName"].(string); ok {
-		return shortName
-	}
-	return ""
-}
 
-// renderDate renders a date node
-func (r *Renderer) renderDate(node *Node) string {
-	if timestamp, ok := node.Attrs["timestamp"].(string); ok {
-		return "[Date: " + timestamp + "]"
+	if user.Name != "" {
+		name := user.Name
+		if !r.options.DisableEscaping {
+			name = escapeText(name, escapeInline)
+		}
+		w.WriteString("@" + name)
+		return
 	}
-	return "[Date]"
+	w.WriteString("@user:" + user.ID)
 }
 
-// renderStatus renders a status node
-func (r *Renderer) renderStatus(node *Node) string {
+// renderEmoji renders an emoji node
+func (r *Renderer) renderEmoji(w *markdownWriter, node *Node) {
 	if text, ok := node.Attrs["text"].(string); ok {
-		return "[" + text + "]"
+		w.WriteString(text)
+		return
 	}
-	return "[STATUS]"
+	if shortName, ok := node.Attrs["shortName"].(string); ok {
+		w.WriteString(shortName)
+		return
+	}
+	w.state.warn(WarningMissingAttr, "emoji has no text or shortName")
 }
 
 // renderMediaSingle renders a mediaSingle node
-func (r *Renderer) renderMediaSingle(node *Node) string {
+func (r *Renderer) renderMediaSingle(w *markdownWriter, node *Node) {
 	if len(node.Content) == 0 {
-		return ""
+		return
 	}
-	
-	var result strings.Builder
-	
+
 	// First content item should be a media node
-	if len(node.Content) > 0 && node.Content[0].Type == "media" {
-		result.WriteString(r.renderMedia(&node.Content[0]))
+	if node.Content[0].Type == "media" {
+		r.renderNode(w, &node.Content[0])
 	}
-	
+
 	// Second content item could be a caption
 	if len(node.Content) > 1 && node.Content[1].Type == "caption" {
-		caption := r.renderCaption(&node.Content[1])
-		if caption != "" {
-			result.WriteString("\n" + caption)
-		}
+		w.ensureNewlines(1)
+		r.renderNode(w, &node.Content[1])
 	}
-	
-	return result.String()
+
+	w.ensureNewlines(2)
 }
 
 // renderMedia renders a media node
-func (r *Renderer) renderMedia(node *Node) string {
+func (r *Renderer) renderMedia(w *markdownWriter, node *Node) {
 	mediaType, _ := node.Attrs["type"].(string)
 	altText, _ := node.Attrs["alt"].(string)
-	
+
 	if altText == "" {
 		altText = "image"
 	}
-	
-	var url string
-	if mediaType == "external" {
-		url, _ = node.Attrs["url"].(string)
-	} else {
-		// For file or link types with collection/id
-		id, _ := node.Attrs["id"].(string)
-		collection, _ := node.Attrs["collection"].(string)
-		url = "/wiki/download/attachments/" + collection + "/" + id
-	}
-	
-	if url != "" {
-		return "![" + altText + "](" + url + ")"
+
+	if url, ok := r.options.mediaURL(node); ok {
+		if !r.options.DisableEscaping {
+			altText = escapeText(altText, escapeLinkLabel)
+			url = linkDestination(url)
+		}
+		w.WriteString("![" + altText + "](" + url + ")")
+		return
 	}
-	
-	return "[Image: " + altText + " - Type: " + mediaType + "]"
+
+	w.state.warn(WarningUnresolvedMedia, "media has no URL or id to link to")
+	w.WriteString("[Image: " + altText + " - Type: " + mediaType + "]")
 }
 
 // renderCaption renders a caption node
-func (r *Renderer) renderCaption(node *Node) string {
-	return "_" + r.renderContent(node.Content) + "_"
+func (r *Renderer) renderCaption(w *markdownWriter, node *Node) {
+	content := r.renderInline(w, node.Content)
+	if content != "" {
+		w.WriteString("_" + content + "_")
+	}
 }
 
 // renderUnknown handles unsupported node types
-func (r *Renderer) renderUnknown(node *Node) string {
-	return "[Unsupported ADF Element: " + node.Type + "]\n"
+func (r *Renderer) renderUnknown(w *markdownWriter, node *Node) {
+	w.state.warn(WarningUnknownNode, "unsupported node "+strconv.Quote(node.Type)+" written as a placeholder")
+	w.WriteString(r.placeholder("Unsupported ADF Element: " + node.Type))
+	w.ensureNewlines(1)
 }
-
-// indentSubsequentLines adds indentation to all lines after the first
-func (r *Renderer) indentSubsequentLines(text string, indent string) string {
-	lines := strings.Split(text, "\n")
-	if len(lines) <= 1 {
-		return text
-	}
-	
-	for i := 1; i < len(lines); i++ {
-		if lines[i] != "" {
-			lines[i] = indent + lines[i]
-		}
-	}
-	
-	return strings.Join(lines, "\n")
-}
\ No newline at end of file
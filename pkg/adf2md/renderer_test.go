@@ -1,6 +1,8 @@
 package adf2md_test
 
 import (
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/carylee/adf2md/pkg/adf2md"
@@ -63,4 +65,145 @@ func TestRenderer(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+// largeDocument builds a document of roughly 1KB of Markdown per section,
+// mixing nested lists, blockquotes, tables and code like a big Confluence
+// export would
+func largeDocument(sections int) *adf2md.Node {
+	paragraph := func(text string) adf2md.Node {
+		return adf2md.Node{Type: "paragraph", Content: []adf2md.Node{
+			{Type: "text", Text: text},
+			{Type: "text", Text: " with bold", Marks: []adf2md.Mark{{Type: "strong"}}},
+			{Type: "text", Text: " and a link", Marks: []adf2md.Mark{{Type: "link", Attrs: map[string]any{"href": "https://example.com"}}}},
+		}}
+	}
+	listItem := func(text string, children ...adf2md.Node) adf2md.Node {
+		return adf2md.Node{Type: "listItem", Content: append([]adf2md.Node{paragraph(text)}, children...)}
+	}
+	cell := func(cellType, text string) adf2md.Node {
+		return adf2md.Node{Type: cellType, Content: []adf2md.Node{paragraph(text)}}
+	}
+
+	doc := &adf2md.Node{Type: "doc", Version: 1}
+	for i := 0; i < sections; i++ {
+		doc.Content = append(doc.Content,
+			adf2md.Node{Type: "heading", Attrs: map[string]any{"level": float64(2)}, Content: []adf2md.Node{{Type: "text", Text: "Section heading"}}},
+			paragraph("Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore"),
+			adf2md.Node{Type: "bulletList", Content: []adf2md.Node{
+				listItem("First item"),
+				listItem("Second item", adf2md.Node{Type: "orderedList", Content: []adf2md.Node{
+					listItem("Nested one"),
+					listItem("Nested two", adf2md.Node{Type: "bulletList", Content: []adf2md.Node{listItem("Deeply nested")}}),
+				}}),
+			}},
+			adf2md.Node{Type: "blockquote", Content: []adf2md.Node{
+				paragraph("Quoted paragraph one"),
+				paragraph("Quoted paragraph two"),
+			}},
+			adf2md.Node{Type: "table", Content: []adf2md.Node{
+				{Type: "tableRow", Content: []adf2md.Node{cell("tableHeader", "Name"), cell("tableHeader", "Value")}},
+				{Type: "tableRow", Content: []adf2md.Node{cell("tableCell", "alpha"), cell("tableCell", "1")}},
+				{Type: "tableRow", Content: []adf2md.Node{cell("tableCell", "beta"), cell("tableCell", "2")}},
+			}},
+			adf2md.Node{Type: "codeBlock", Attrs: map[string]any{"language": "go"}, Content: []adf2md.Node{{Type: "text", Text: "func main() {\n\tfmt.Println(\"Hello\")\n}"}}},
+		)
+	}
+	return doc
+}
+
+// benchmarkSizes are the documents the benchmarks render, from a single
+// page up to a multi-megabyte export
+var benchmarkSizes = []struct {
+	name     string
+	sections int
+}{
+	{"40KB", 40},
+	{"4MB", 4000},
+	{"16MB", 16000},
+}
+
+// BenchmarkRenderToMarkdown only uses the public API, so it also runs
+// against the string-concatenating renderer that the streaming writer
+// replaced. That renderer is gone from the tree; to compare, copy this
+// benchmark and largeDocument into a checkout of the commit before it.
+func BenchmarkRenderToMarkdown(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(size.name, func(b *testing.B) {
+			doc := largeDocument(size.sections)
+			renderer := adf2md.NewRenderer()
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				markdown, err := renderer.RenderToMarkdown(doc)
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.SetBytes(int64(len(markdown)))
+			}
+		})
+	}
+}
+
+func BenchmarkRender(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(size.name, func(b *testing.B) {
+			doc := largeDocument(size.sections)
+			renderer := adf2md.NewRenderer()
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var counter countingWriter
+				if err := renderer.Render(&counter, doc); err != nil {
+					b.Fatal(err)
+				}
+				b.SetBytes(int64(counter))
+			}
+		})
+	}
+}
+
+// countingWriter discards what is written to it, counting the bytes
+type countingWriter int
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	*c += countingWriter(len(p))
+	return io.Discard.Write(p)
+}
+
+func TestRenderMatchesRenderToMarkdown(t *testing.T) {
+	doc := largeDocument(3)
+	renderer := adf2md.NewRenderer()
+
+	expected, err := renderer.RenderToMarkdown(doc)
+	if err != nil {
+		t.Fatalf("RenderToMarkdown failed: %v", err)
+	}
+
+	var result strings.Builder
+	if err := renderer.Render(&result, doc); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	if result.String() != expected {
+		t.Errorf("\nExpected: %q\nGot:      %q", expected, result.String())
+	}
+}
+
+func TestRenderWriteError(t *testing.T) {
+	node, err := adf2md.ParseADF(`{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"Hello"}]}]}`)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	if err := adf2md.NewRenderer().Render(failingWriter{}, node); err == nil {
+		t.Error("Render() expected an error from the writer")
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
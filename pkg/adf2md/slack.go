@@ -0,0 +1,315 @@
+package adf2md
+
+import (
+	"strings"
+	"time"
+)
+
+// NewSlackRenderer creates a renderer that writes Slack mrkdwn, the markup
+// of Slack messages, instead of Markdown. It works like any other Renderer:
+// RenderToMarkdown, RenderToResult and Render return mrkdwn, and custom
+// node, mark and extension renderers can be registered.
+//
+// Slack has no headings, lists or tables, so headings become bold lines,
+// lists are marked with bullet glyphs and numbers, and tables are laid out
+// as text in a preformatted block. Mentions of users whose SlackID is known
+// to the MentionResolver become <@U0123ABCD> references.
+func NewSlackRenderer() *Renderer {
+	r := NewRenderer()
+	r.syntax = slackSyntax
+	return r
+}
+
+// slackLists renders lists as plain text does, with bullet glyphs and
+// numbers, as Slack has no list syntax
+var slackLists = &PlainTextRenderer{}
+
+// slackSyntax is the built-in rendering of Slack mrkdwn
+var slackSyntax = &syntax{
+	nodes: map[string]func(r *Renderer, w *markdownWriter, node *Node){
+		"heading":       (*Renderer).renderSlackHeading,
+		"bulletList":    slackLists.renderList,
+		"orderedList":   slackLists.renderList,
+		"taskList":      slackLists.renderList,
+		"decisionList":  slackLists.renderList,
+		"listItem":      slackLists.renderListItem,
+		"taskItem":      slackLists.renderListItem,
+		"decisionItem":  slackLists.renderListItem,
+		"codeBlock":     (*Renderer).renderSlackCodeBlock,
+		"rule":          (*Renderer).renderSlackRule,
+		"hardBreak":     (*Renderer).renderSlackHardBreak,
+		"panel":         (*Renderer).renderSlackPanel,
+		"mention":       (*Renderer).renderSlackMention,
+		"date":          (*Renderer).renderSlackDate,
+		"status":        (*Renderer).renderSlackStatus,
+		"media":         (*Renderer).renderSlackMedia,
+		"mediaGroup":    (*Renderer).renderSlackMediaGroup,
+		"mediaInline":   (*Renderer).renderSlackMedia,
+		"caption":       (*Renderer).renderSlackCaption,
+		"inlineCard":    (*Renderer).renderSlackCard,
+		"blockCard":     (*Renderer).renderSlackCard,
+		"embedCard":     (*Renderer).renderSlackCard,
+		"expand":        (*Renderer).renderSlackExpand,
+		"nestedExpand":  (*Renderer).renderSlackExpand,
+		"layoutSection": (*Renderer).renderSlackLayout,
+		"table":         (*Renderer).renderSlackTable,
+	},
+	marks: map[string]MarkRenderer{
+		"strong": delimiters("*", "*"),
+		"em":     delimiters("_", "_"),
+		"strike": delimiters("~", "~"),
+		"code":   delimiters("`", "`"),
+		"link": MarkRendererFunc(func(ctx *RenderContext, mark *Mark, text string) (string, string) {
+			href, _ := mark.Attrs["href"].(string)
+			if href == "" {
+				ctx.Warn(WarningMissingAttr, "link has no href")
+				return "", ""
+			}
+			return "<" + escapeSlack(href) + "|", ">"
+		}),
+		"underline":       droppedMark("underline dropped, as Slack has none"),
+		"textColor":       droppedMark("text color dropped, as Slack has none"),
+		"backgroundColor": droppedMark("background color dropped, as Slack has none"),
+		"subsup":          droppedMark("subscript or superscript dropped, as Slack has none"),
+	},
+	escape:           escapeSlack,
+	escapeCode:       true,
+	escapeLineStarts: func(text string, atLineStart bool) string { return text },
+	placeholder: func(text string) string {
+		return "[" + text + "]"
+	},
+}
+
+// droppedMark returns a MarkRenderer that leaves text as it is and warns
+// that the mark was lost
+func droppedMark(message string) MarkRenderer {
+	return MarkRendererFunc(func(ctx *RenderContext, mark *Mark, text string) (string, string) {
+		ctx.w.state.warnMark(WarningLossy, mark, message)
+		return "", ""
+	})
+}
+
+// renderSlackHeading renders a heading as a bold line
+func (r *Renderer) renderSlackHeading(w *markdownWriter, node *Node) {
+	if content := r.renderInline(w, node.Content); content != "" {
+		w.WriteString("*" + content + "*")
+	}
+	w.ensureNewlines(2)
+}
+
+// renderSlackCodeBlock renders a code block in ``` fences. Slack doesn't
+// highlight code, so the language is dropped.
+func (r *Renderer) renderSlackCodeBlock(w *markdownWriter, node *Node) {
+	var code strings.Builder
+	for _, child := range node.Content {
+		code.WriteString(child.Text)
+	}
+	w.WriteString("```\n" + r.escapeSlack(code.String()) + "\n```")
+	w.ensureNewlines(2)
+}
+
+// escapeSlack escapes text unless escaping is disabled
+func (r *Renderer) escapeSlack(text string) string {
+	if r.options.DisableEscaping {
+		return text
+	}
+	return escapeSlack(text)
+}
+
+// renderSlackRule renders a horizontal rule as a line of dashes
+func (r *Renderer) renderSlackRule(w *markdownWriter, node *Node) {
+	w.WriteString("----------")
+	w.ensureNewlines(2)
+}
+
+// renderSlackHardBreak renders a line break
+func (r *Renderer) renderSlackHardBreak(w *markdownWriter, node *Node) {
+	w.WriteString("\n")
+}
+
+// slackPanelEmoji maps panel types to the Slack emoji that starts them
+var slackPanelEmoji = map[string]string{
+	"info":    ":information_source:",
+	"note":    ":memo:",
+	"warning": ":warning:",
+	"error":   ":x:",
+	"success": ":white_check_mark:",
+	"tip":     ":bulb:",
+}
+
+// renderSlackPanel renders a panel as a quote starting with an emoji for
+// its type, or the icon of a custom panel
+func (r *Renderer) renderSlackPanel(w *markdownWriter, node *Node) {
+	panelType, _ := node.Attrs["panelType"].(string)
+	icon, ok := slackPanelEmoji[panelType]
+	if !ok {
+		icon = panelIcon(node)
+	}
+
+	w.pushPrefix("> ", "> ")
+	if icon != "" {
+		w.WriteString(icon + " ")
+	}
+	r.renderContent(w, node.Content)
+	w.popPrefix()
+	w.ensureNewlines(2)
+}
+
+// renderSlackMention renders a mention as a reference to the user's Slack
+// account, such as <@U0123ABCD>, or as @name if their Slack ID isn't known
+func (r *Renderer) renderSlackMention(w *markdownWriter, node *Node) {
+	user := r.options.mentionUser(node)
+	if user.SlackID != "" {
+		w.WriteString("<@" + user.SlackID + ">")
+		return
+	}
+
+	if user.Name == "" {
+		if user.ID == "" {
+			w.state.warn(WarningMissingAttr, "mention has no text or id")
+		}
+		user.Name = user.ID
+	}
+	w.WriteString("@" + r.escapeSlack(user.Name))
+}
+
+// renderSlackDate renders a date as text in the configured layout, or as
+// an ISO 8601 timestamp with DateStyleISO
+func (r *Renderer) renderSlackDate(w *markdownWriter, node *Node) {
+	t, text, ok := r.options.formatDate(w.state, node)
+	if ok && r.options.DateStyle == DateStyleISO {
+		text = t.Format(time.RFC3339)
+	}
+	w.WriteString(r.escapeSlack(text))
+}
+
+// renderSlackStatus renders a status lozenge as its text in brackets, in
+// code with StatusStyleCode or after a colored circle with StatusStyleEmoji
+func (r *Renderer) renderSlackStatus(w *markdownWriter, node *Node) {
+	text, ok := node.Attrs["text"].(string)
+	if !ok {
+		w.state.warn(WarningMissingAttr, "status has no text")
+		text = "STATUS"
+	}
+	text = r.escapeSlack(text)
+
+	switch r.options.StatusStyle {
+	case StatusStyleCode:
+		w.WriteString("`" + text + "`")
+	case StatusStyleEmoji:
+		color, _ := node.Attrs["color"].(string)
+		colors, ok := statusColors[color]
+		if !ok {
+			colors = statusColors["neutral"]
+		}
+		w.WriteString(colors.emoji + " " + text)
+	default:
+		w.WriteString("[" + text + "]")
+	}
+}
+
+// renderSlackMedia renders an image or attachment as a link labelled with
+// its name. Slack only unfurls images posted on their own, so they can't
+// be embedded.
+func (r *Renderer) renderSlackMedia(w *markdownWriter, node *Node) {
+	name, _ := node.Attrs["alt"].(string)
+	if name == "" {
+		name, _ = node.Attrs["id"].(string)
+	}
+	if name == "" {
+		name = "attachment"
+	}
+	name = r.escapeSlack(name)
+
+	url, ok := r.options.mediaURL(node)
+	if !ok {
+		w.state.warn(WarningUnresolvedMedia, node.Type+" has no URL or id to link to")
+		w.WriteString("[Attachment: " + name + "]")
+		return
+	}
+	w.WriteString("<" + r.escapeSlack(url) + "|" + name + ">")
+}
+
+// renderSlackMediaGroup renders a mediaGroup node as a list of links to
+// the files
+func (r *Renderer) renderSlackMediaGroup(w *markdownWriter, node *Node) {
+	for i := range node.Content {
+		media := &node.Content[i]
+		if media.Type != "media" {
+			continue
+		}
+
+		w.ensureNewlines(1)
+		w.WriteString(DefaultBullets[0] + " ")
+		w.state.enter(media)
+		r.renderSlackMedia(w, media)
+		w.state.leave()
+	}
+	w.ensureNewlines(2)
+}
+
+// renderSlackCaption renders a caption in italics
+func (r *Renderer) renderSlackCaption(w *markdownWriter, node *Node) {
+	if content := r.renderInline(w, node.Content); content != "" {
+		w.WriteString("_" + content + "_")
+	}
+}
+
+// renderSlackCard renders a smart link as a link labelled with the linked
+// page's name if it is known
+func (r *Renderer) renderSlackCard(w *markdownWriter, node *Node) {
+	url, name, _ := cardDetails(node)
+	if url == "" {
+		w.state.warn(WarningMissingAttr, node.Type+" has no url")
+		return
+	}
+
+	if name == "" || r.options.CardStyle == CardStyleAutolink {
+		w.WriteString("<" + r.escapeSlack(url) + ">")
+	} else {
+		w.WriteString("<" + r.escapeSlack(url) + "|" + r.escapeSlack(name) + ">")
+	}
+
+	if node.Type != "inlineCard" {
+		w.ensureNewlines(2)
+	}
+}
+
+// renderSlackExpand renders an expand as its title in bold followed by its
+// content
+func (r *Renderer) renderSlackExpand(w *markdownWriter, node *Node) {
+	if title, _ := node.Attrs["title"].(string); title != "" {
+		w.WriteString("*" + r.escapeSlack(title) + "*")
+		w.ensureNewlines(2)
+	}
+	r.renderContent(w, node.Content)
+	w.ensureNewlines(2)
+}
+
+// renderSlackLayout renders the columns of a layout one after another
+func (r *Renderer) renderSlackLayout(w *markdownWriter, node *Node) {
+	r.renderSequentialLayout(w, layoutColumns(node))
+}
+
+// renderSlackTable renders a table as plain text columns in a preformatted
+// block, as Slack has no tables
+func (r *Renderer) renderSlackTable(w *markdownWriter, node *Node) {
+	plain := NewPlainTextRenderer().WithOptions(PlainTextOptions{RenderOptions: r.options})
+
+	var table strings.Builder
+	text := w.subWriter(&table)
+	plain.renderTable(plain.renderer(), text, node)
+	w.fail(text.err)
+
+	w.WriteString("```\n" + r.escapeSlack(strings.TrimRight(table.String(), "\n")) + "\n```")
+	w.ensureNewlines(2)
+}
+
+// escapeSlack escapes the characters Slack reads as markup: &, < and >.
+// Slack has no way to escape its formatting characters, such as *.
+func escapeSlack(text string) string {
+	if !strings.ContainsAny(text, "&<>") {
+		return text
+	}
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
@@ -0,0 +1,113 @@
+package adf2md_test
+
+import (
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestRenderSlack(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "Heading and marks",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"heading","attrs":{"level":2},"content":[{"type":"text","text":"Release notes"}]},
+				{"type":"paragraph","content":[
+					{"type":"text","text":"bold","marks":[{"type":"strong"}]},
+					{"type":"text","text":" "},
+					{"type":"text","text":"italic","marks":[{"type":"em"}]},
+					{"type":"text","text":" "},
+					{"type":"text","text":"gone","marks":[{"type":"strike"}]},
+					{"type":"text","text":" "},
+					{"type":"text","text":"a<b","marks":[{"type":"code"}]},
+					{"type":"text","text":" "},
+					{"type":"text","text":"docs","marks":[{"type":"link","attrs":{"href":"https://example.com/?a=1&b=2"}}]}
+				]}
+			]}`,
+			expected: "*Release notes*\n\n*bold* _italic_ ~gone~ `a&lt;b` <https://example.com/?a=1&amp;b=2|docs>\n\n",
+		},
+		{
+			name: "Escaping",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"paragraph","content":[{"type":"text","text":"Tom & Jerry <3 > 2"}]}
+			]}`,
+			expected: "Tom &amp; Jerry &lt;3 &gt; 2\n\n",
+		},
+		{
+			name: "Mentions",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"paragraph","content":[
+					{"type":"mention","attrs":{"id":"1","text":"@Jane"}},
+					{"type":"text","text":" "},
+					{"type":"mention","attrs":{"id":"2","text":"@Sam"}}
+				]}
+			]}`,
+			expected: "<@U0123ABCD> @Sam\n\n",
+		},
+		{
+			name: "Lists",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"bulletList","content":[
+					{"type":"listItem","content":[
+						{"type":"paragraph","content":[{"type":"text","text":"One"}]},
+						{"type":"orderedList","content":[
+							{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"First"}]}]}
+						]}
+					]}
+				]}
+			]}`,
+			expected: "• One\n  1. First\n\n",
+		},
+		{
+			name: "Code block and panel",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"codeBlock","attrs":{"language":"go"},"content":[{"type":"text","text":"if a < b {}"}]},
+				{"type":"panel","attrs":{"panelType":"warning"},"content":[{"type":"paragraph","content":[{"type":"text","text":"Mind the gap"}]}]}
+			]}`,
+			expected: "```\nif a &lt; b {}\n```\n\n> :warning: Mind the gap\n\n",
+		},
+		{
+			name: "Table",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"table","content":[
+					{"type":"tableRow","content":[
+						{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Service"}]}]},
+						{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Owner"}]}]}
+					]},
+					{"type":"tableRow","content":[
+						{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"api","marks":[{"type":"strong"}]}]}]},
+						{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"R&D"}]}]}
+					]}
+				]}
+			]}`,
+			expected: "```\nService  Owner\n-------  -----\napi      R&amp;D\n```\n\n",
+		},
+	}
+
+	users := adf2md.UserMap{
+		"1": {ID: "1", Name: "Jane Doe", SlackID: "U0123ABCD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := adf2md.ParseADF(tt.input)
+			if err != nil {
+				t.Fatalf("Failed to parse ADF: %v", err)
+			}
+
+			renderer := adf2md.NewSlackRenderer().WithOptions(adf2md.RenderOptions{MentionResolver: users})
+			result, err := renderer.RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
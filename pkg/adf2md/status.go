@@ -0,0 +1,86 @@
+package adf2md
+
+import (
+	"html"
+	"net/url"
+	"strings"
+)
+
+// StatusStyle controls how status lozenges (status nodes) are rendered
+type StatusStyle int
+
+const (
+	// StatusStyleBrackets writes the status text in square brackets, such
+	// as [DONE]
+	StatusStyleBrackets StatusStyle = iota
+	// StatusStyleCode writes the status text as inline code
+	StatusStyleCode
+	// StatusStyleEmoji writes the status text after a colored circle
+	// emoji, such as 🟢 DONE
+	StatusStyleEmoji
+	// StatusStyleHTML writes an HTML span with the color as a class, such
+	// as <span class="status status-green">DONE</span>
+	StatusStyleHTML
+	// StatusStyleBadge writes a shields.io badge image in the status color
+	StatusStyleBadge
+)
+
+// statusColors maps status colors to the emoji and shields.io color used
+// for them
+var statusColors = map[string]struct{ emoji, badge string }{
+	"neutral": {"⚪", "lightgrey"},
+	"purple":  {"🟣", "blueviolet"},
+	"blue":    {"🔵", "blue"},
+	"red":     {"🔴", "red"},
+	"yellow":  {"🟡", "yellow"},
+	"green":   {"🟢", "green"},
+}
+
+// renderStatus renders a status node in the configured StatusStyle
+func (r *Renderer) renderStatus(w *markdownWriter, node *Node) {
+	text, ok := node.Attrs["text"].(string)
+	if !ok {
+		w.state.warn(WarningMissingAttr, "status has no text")
+		w.WriteString("[STATUS]")
+		return
+	}
+
+	// Statuses without a known color are shown as neutral
+	color, _ := node.Attrs["color"].(string)
+	if _, ok := statusColors[color]; !ok {
+		color = "neutral"
+	}
+
+	switch r.options.StatusStyle {
+	case StatusStyleCode:
+		open, close := "`", "`"
+		if !r.options.DisableEscaping {
+			open, close = codeSpanDelimiters(text)
+		}
+		w.WriteString(open + text + close)
+	case StatusStyleEmoji:
+		w.WriteString(statusColors[color].emoji + " " + r.statusText(text))
+	case StatusStyleHTML:
+		w.WriteString(`<span class="status status-` + color + `">` + html.EscapeString(text) + "</span>")
+	case StatusStyleBadge:
+		w.WriteString("![" + r.statusText(text) + "](" + statusBadgeURL(text, statusColors[color].badge) + ")")
+	default:
+		w.WriteString("[" + r.statusText(text) + "]")
+	}
+}
+
+// statusText escapes the text of a status unless escaping is disabled
+func (r *Renderer) statusText(text string) string {
+	if r.options.DisableEscaping {
+		return text
+	}
+	return escapeText(text, escapeLinkLabel)
+}
+
+// statusBadgeURL returns the URL of a shields.io static badge showing text.
+// Dashes and underscores are doubled, as single ones separate the parts of
+// the badge and stand for spaces.
+func statusBadgeURL(text, color string) string {
+	text = strings.NewReplacer("-", "--", "_", "__").Replace(text)
+	return "https://img.shields.io/badge/" + url.PathEscape(text) + "-" + color
+}
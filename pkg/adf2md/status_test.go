@@ -0,0 +1,68 @@
+package adf2md_test
+
+import (
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestRenderStatus(t *testing.T) {
+	input := `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[
+		{"type":"status","attrs":{"text":"DONE","color":"green"}},
+		{"type":"text","text":" "},
+		{"type":"status","attrs":{"text":"IN-REVIEW <2>","color":"purple"}},
+		{"type":"text","text":" "},
+		{"type":"status","attrs":{"text":"NEW","color":"pink"}}
+	]}]}`
+
+	tests := []struct {
+		name     string
+		style    adf2md.StatusStyle
+		expected string
+	}{
+		{
+			name:     "Brackets",
+			style:    adf2md.StatusStyleBrackets,
+			expected: "[DONE] [IN-REVIEW <2>] [NEW]\n\n",
+		},
+		{
+			name:     "Code",
+			style:    adf2md.StatusStyleCode,
+			expected: "`DONE` `IN-REVIEW <2>` `NEW`\n\n",
+		},
+		{
+			name:     "Emoji",
+			style:    adf2md.StatusStyleEmoji,
+			expected: "🟢 DONE 🟣 IN-REVIEW <2> ⚪ NEW\n\n",
+		},
+		{
+			name:     "HTML",
+			style:    adf2md.StatusStyleHTML,
+			expected: "<span class=\"status status-green\">DONE</span> <span class=\"status status-purple\">IN-REVIEW &lt;2&gt;</span> <span class=\"status status-neutral\">NEW</span>\n\n",
+		},
+		{
+			name:     "Badge",
+			style:    adf2md.StatusStyleBadge,
+			expected: "![DONE](https://img.shields.io/badge/DONE-green) ![IN-REVIEW <2>](https://img.shields.io/badge/IN--REVIEW%20%3C2%3E-blueviolet) ![NEW](https://img.shields.io/badge/NEW-lightgrey)\n\n",
+		},
+	}
+
+	node, err := adf2md.ParseADF(input)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := adf2md.NewRenderer().WithOptions(adf2md.RenderOptions{StatusStyle: tt.style})
+			result, err := renderer.RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,900 @@
+package adf2md
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// storageElement is an element of Confluence storage format, or a run of
+// text if name is empty. Names keep their namespace prefix, as in
+// "ac:structured-macro" or "ri:attachment".
+type storageElement struct {
+	name     string
+	attrs    map[string]string
+	children []*storageElement
+	text     string
+}
+
+// child returns the first child element with the given name, or nil
+func (e *storageElement) child(name string) *storageElement {
+	if e == nil {
+		return nil
+	}
+	for _, child := range e.children {
+		if child.name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// textContent returns all the text inside the element
+func (e *storageElement) textContent() string {
+	if e == nil {
+		return ""
+	}
+	if e.name == "" {
+		return e.text
+	}
+	var text strings.Builder
+	for _, child := range e.children {
+		text.WriteString(child.textContent())
+	}
+	return text.String()
+}
+
+// params returns the ac:parameter values of a macro, keyed by name
+func (e *storageElement) params() map[string]string {
+	params := map[string]string{}
+	for _, child := range e.children {
+		if child.name == "ac:parameter" {
+			params[child.attrs["ac:name"]] = child.textContent()
+		}
+	}
+	return params
+}
+
+// storageParser converts Confluence storage format into ADF nodes. It
+// keeps counters for the localId attrs of task lists and items that don't
+// have a task ID.
+type storageParser struct {
+	options   StorageOptions
+	taskLists int
+	tasks     int
+}
+
+// StorageOptions contains configuration for parsing storage format
+type StorageOptions struct {
+	// The ID of the Confluence page the storage format belongs to. Storage
+	// format doesn't record it, but attachments need it to be found: they
+	// are put in the page's collection, contentId-<PageID>.
+	PageID string
+}
+
+// ParseStorage parses Confluence storage format, the XHTML that Confluence
+// stores pages in and its REST API returns as body.storage, into an ADF
+// document. Headings, lists, tables, links and text formatting map to
+// their ADF nodes and marks, as do task lists, images, user links,
+// emoticons and the code, noformat, info, note, warning, tip, panel,
+// status and expand macros. Other macros become extension nodes with their
+// parameters, for an ExtensionHandler to render.
+//
+// Storage format allows nesting that ADF doesn't, such as a panel inside a
+// panel or a table inside a table cell. These are flattened into content
+// that fits, so the document passes Validate.
+//
+// Storage format doesn't record which page it belongs to, so attachments
+// only keep their file name and can't be linked to. Use
+// ParseStorageWithOptions to give the page ID.
+func ParseStorage(storage string) (*Node, error) {
+	return ParseStorageWithOptions(storage, StorageOptions{})
+}
+
+// ParseStorageWithOptions parses Confluence storage format like
+// ParseStorage, with the given options
+func ParseStorageWithOptions(storage string, options StorageOptions) (*Node, error) {
+	if !utf8.ValidString(storage) {
+		return nil, errors.New("invalid UTF-8 in storage format input")
+	}
+
+	root, err := parseStorageTree(storage)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &storageParser{options: options}
+	return &Node{
+		Type:    "doc",
+		Version: 1,
+		Content: fitBlocks("doc", p.blocks(root.children, false)),
+	}, nil
+}
+
+// parseStorageTree parses storage format into a tree of elements. Storage
+// format is a fragment with undeclared namespace prefixes and HTML
+// entities such as &nbsp;, so it is read leniently within a root element.
+func parseStorageTree(storage string) (*storageElement, error) {
+	decoder := xml.NewDecoder(strings.NewReader("<storage>" + storage + "</storage>"))
+	decoder.Strict = false
+	decoder.AutoClose = storageAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	// The root's only child is the <storage> element around the input
+	root := &storageElement{}
+	stack := []*storageElement{root}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			if len(root.children) == 0 {
+				return root, nil
+			}
+			return root.children[0], nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing storage format: %w", err)
+		}
+
+		parent := stack[len(stack)-1]
+		switch token := token.(type) {
+		case xml.StartElement:
+			element := &storageElement{name: storageName(token.Name), attrs: map[string]string{}}
+			for _, attr := range token.Attr {
+				element.attrs[storageName(attr.Name)] = attr.Value
+			}
+			parent.children = append(parent.children, element)
+			stack = append(stack, element)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.children = append(parent.children, &storageElement{text: string(token)})
+		}
+	}
+}
+
+// storageAutoClose lists the HTML elements that may be written without
+// being closed, such as <br>. It leaves out <link>, which shares its local
+// name with <ac:link>.
+var storageAutoClose = []string{"br", "hr", "img", "col", "area", "input", "wbr"}
+
+// storageName returns an element or attribute name with its prefix
+func storageName(name xml.Name) string {
+	if name.Space == "" {
+		return strings.ToLower(name.Local)
+	}
+	return name.Space + ":" + name.Local
+}
+
+// storageBlocks are the elements that are converted to block nodes
+var storageBlocks = map[string]bool{
+	"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true, "ul": true, "ol": true, "blockquote": true, "pre": true,
+	"hr": true, "table": true, "div": true, "ac:task-list": true,
+	"ac:layout": true,
+}
+
+// storageBlockMacros are the macros that are always converted to block
+// nodes, even inside a paragraph
+var storageBlockMacros = map[string]bool{
+	"code": true, "noformat": true, "info": true, "note": true,
+	"warning": true, "tip": true, "panel": true, "expand": true,
+}
+
+// blocks converts the children of a container element into block nodes.
+// Runs of text and inline elements are gathered into paragraphs. Macros
+// without a body are inline inside a paragraph and blocks elsewhere.
+func (p *storageParser) blocks(elements []*storageElement, inParagraph bool) []Node {
+	var blocks, inline []Node
+	for _, e := range elements {
+		block := storageBlocks[e.name]
+		if e.name == "ac:structured-macro" {
+			name := e.attrs["ac:name"]
+			block = storageBlockMacros[name] || e.child("ac:rich-text-body") != nil ||
+				(!inParagraph && name != "status")
+		}
+
+		if !block {
+			inline = append(inline, p.inlines(e, nil)...)
+			continue
+		}
+		blocks = append(blocks, p.paragraph(inline)...)
+		inline = nil
+		blocks = append(blocks, p.block(e)...)
+	}
+	return append(blocks, p.paragraph(inline)...)
+}
+
+// block converts a block element into nodes
+func (p *storageParser) block(e *storageElement) []Node {
+	switch e.name {
+	case "p":
+		return p.blocks(e.children, true)
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		return []Node{{
+			Type:    "heading",
+			Attrs:   map[string]any{"level": float64(e.name[1] - '0')},
+			Content: p.inlineContent(e.children),
+		}}
+	case "ul", "ol":
+		return []Node{p.list(e)}
+	case "blockquote":
+		return []Node{{Type: "blockquote", Content: fitBlocks("blockquote", p.blocks(e.children, false))}}
+	case "pre":
+		node := Node{Type: "codeBlock"}
+		if text := e.textContent(); text != "" {
+			node.Content = []Node{{Type: "text", Text: text}}
+		}
+		return []Node{node}
+	case "hr":
+		return []Node{{Type: "rule"}}
+	case "table":
+		return []Node{p.table(e)}
+	case "ac:task-list":
+		return p.taskList(e)
+	case "ac:layout":
+		return p.layout(e)
+	case "ac:structured-macro":
+		return p.macro(e, false)
+	}
+	return p.blocks(e.children, false)
+}
+
+// fitBlocks makes blocks fit the content model of the node that holds
+// them. Storage format lets almost anything nest, but ADF doesn't: an
+// expand inside another becomes a nestedExpand, headings become
+// paragraphs and block macros inline macros, and other containers, such
+// as a panel inside a panel or a table inside a table cell, are replaced
+// by their content. Anything else is flattened into a paragraph, as task
+// bodies are. A listItem that would start with something other than a
+// paragraph gets an empty one first.
+func fitBlocks(parent string, blocks []Node) []Node {
+	allowed := nodeSpecs[parent].content
+	var fitted []Node
+	for _, block := range blocks {
+		switch {
+		case contains(allowed, block.Type):
+			fitted = append(fitted, block)
+		case block.Type == "expand" && contains(allowed, "nestedExpand"):
+			block.Type = "nestedExpand"
+			block.Content = fitBlocks("nestedExpand", block.Content)
+			fitted = append(fitted, block)
+		case block.Type == "heading" && contains(allowed, "paragraph"):
+			fitted = append(fitted, Node{Type: "paragraph", Content: block.Content})
+		case block.Type == "extension" && contains(allowed, "paragraph"):
+			block.Type = "inlineExtension"
+			fitted = append(fitted, Node{Type: "paragraph", Content: []Node{block}})
+		default:
+			if content, ok := containedBlocks(&block); ok {
+				fitted = append(fitted, fitBlocks(parent, content)...)
+			} else if inline := flattenInline(&block); len(inline) > 0 && contains(allowed, "paragraph") {
+				fitted = append(fitted, Node{Type: "paragraph", Content: inline})
+			}
+		}
+	}
+
+	if parent == "listItem" && len(fitted) > 0 {
+		switch fitted[0].Type {
+		case "paragraph", "codeBlock", "mediaSingle":
+		default:
+			fitted = append([]Node{{Type: "paragraph"}}, fitted...)
+		}
+	}
+	return fitted
+}
+
+// containedBlocks returns the blocks inside a container node that is
+// replaced by its content where it can't be nested. An expand's title
+// leads its content in bold, as a panel's does.
+func containedBlocks(node *Node) ([]Node, bool) {
+	switch node.Type {
+	case "panel", "blockquote", "bodiedExtension":
+		return node.Content, true
+	case "expand", "nestedExpand":
+		title, _ := node.Attrs["title"].(string)
+		if title == "" {
+			return node.Content, true
+		}
+		heading := Node{Type: "paragraph", Content: []Node{textNode(title, []Mark{{Type: "strong"}})}}
+		return append([]Node{heading}, node.Content...), true
+	case "table", "tableRow", "layoutSection":
+		// The content of each cell or column in turn
+		var content []Node
+		for i := range node.Content {
+			cells, _ := containedBlocks(&node.Content[i])
+			content = append(content, cells...)
+		}
+		return content, true
+	case "tableHeader", "tableCell", "layoutColumn":
+		return node.Content, true
+	}
+	return nil, false
+}
+
+// paragraph builds a paragraph from inline nodes, or mediaSingle nodes if
+// it holds nothing but images. Nothing is returned if it is empty.
+func (p *storageParser) paragraph(inline []Node) []Node {
+	inline = trimInline(inline)
+	if len(inline) == 0 {
+		return nil
+	}
+
+	var images []Node
+	for _, node := range inline {
+		switch {
+		case node.Type == "mediaSingle":
+			images = append(images, node)
+		case node.Type == "text" && strings.TrimSpace(node.Text) == "":
+		default:
+			return []Node{{Type: "paragraph", Content: inlineMedia(inline)}}
+		}
+	}
+	return images
+}
+
+// inlineContent converts the children of an element that can only hold
+// inline content, such as a heading
+func (p *storageParser) inlineContent(elements []*storageElement) []Node {
+	var inline []Node
+	for _, e := range elements {
+		inline = append(inline, p.inlines(e, nil)...)
+	}
+	return inlineMedia(trimInline(inline))
+}
+
+// list converts a ul or ol element into a bulletList or orderedList
+func (p *storageParser) list(e *storageElement) Node {
+	list := Node{Type: "bulletList"}
+	if e.name == "ol" {
+		list.Type = "orderedList"
+		if start, err := strconv.Atoi(e.attrs["start"]); err == nil && start != 1 {
+			list.Attrs = map[string]any{"order": float64(start)}
+		}
+	}
+
+	for _, li := range e.children {
+		if li.name != "li" {
+			continue
+		}
+		content := fitBlocks("listItem", p.blocks(li.children, false))
+		if len(content) == 0 {
+			content = []Node{{Type: "paragraph"}}
+		}
+		list.Content = append(list.Content, Node{Type: "listItem", Content: content})
+	}
+	return list
+}
+
+// table converts a table element, whose rows may be grouped in thead,
+// tbody and tfoot elements
+func (p *storageParser) table(e *storageElement) Node {
+	table := Node{Type: "table"}
+	for _, child := range e.children {
+		switch child.name {
+		case "tr":
+			table.Content = append(table.Content, p.tableRow(child))
+		case "thead", "tbody", "tfoot":
+			for _, tr := range child.children {
+				if tr.name == "tr" {
+					table.Content = append(table.Content, p.tableRow(tr))
+				}
+			}
+		}
+	}
+	return table
+}
+
+// tableRow converts a tr element, keeping the spans of merged cells
+func (p *storageParser) tableRow(e *storageElement) Node {
+	row := Node{Type: "tableRow"}
+	for _, cell := range e.children {
+		if cell.name != "th" && cell.name != "td" {
+			continue
+		}
+
+		node := Node{Type: "tableCell"}
+		if cell.name == "th" {
+			node.Type = "tableHeader"
+		}
+		node.Content = fitBlocks(node.Type, p.blocks(cell.children, false))
+		if len(node.Content) == 0 {
+			node.Content = []Node{{Type: "paragraph"}}
+		}
+		for _, attr := range []string{"colspan", "rowspan"} {
+			if span, err := strconv.Atoi(cell.attrs[attr]); err == nil && span > 1 {
+				if node.Attrs == nil {
+					node.Attrs = map[string]any{}
+				}
+				node.Attrs[attr] = float64(span)
+			}
+		}
+		row.Content = append(row.Content, node)
+	}
+	return row
+}
+
+// taskList converts an ac:task-list element. A taskItem only holds inline
+// content, so task lists nested in a task become siblings of the task and
+// any other blocks are joined with line breaks.
+func (p *storageParser) taskList(e *storageElement) []Node {
+	p.taskLists++
+	list := Node{
+		Type:  "taskList",
+		Attrs: map[string]any{"localId": fmt.Sprintf("task-list-%d", p.taskLists)},
+	}
+
+	for _, task := range e.children {
+		if task.name != "ac:task" {
+			continue
+		}
+
+		p.tasks++
+		id := strings.TrimSpace(task.child("ac:task-id").textContent())
+		if id == "" {
+			id = fmt.Sprintf("task-%d", p.tasks)
+		}
+		state := "TODO"
+		if strings.TrimSpace(task.child("ac:task-status").textContent()) == "complete" {
+			state = "DONE"
+		}
+		item := Node{Type: "taskItem", Attrs: map[string]any{"localId": id, "state": state}}
+
+		var nested []Node
+		if body := task.child("ac:task-body"); body != nil {
+			for _, block := range p.blocks(body.children, false) {
+				if block.Type == "taskList" {
+					nested = append(nested, block)
+					continue
+				}
+				if len(item.Content) > 0 {
+					item.Content = append(item.Content, Node{Type: "hardBreak"})
+				}
+				if block.Type == "paragraph" {
+					item.Content = append(item.Content, block.Content...)
+				} else {
+					item.Content = append(item.Content, flattenInline(&block)...)
+				}
+			}
+		}
+
+		list.Content = append(list.Content, item)
+		list.Content = append(list.Content, nested...)
+	}
+	return []Node{list}
+}
+
+// layout converts an ac:layout element into a layoutSection for each of
+// its sections, with the columns sharing the width equally
+func (p *storageParser) layout(e *storageElement) []Node {
+	var sections []Node
+	for _, section := range e.children {
+		if section.name != "ac:layout-section" {
+			continue
+		}
+
+		var cells []*storageElement
+		for _, cell := range section.children {
+			if cell.name == "ac:layout-cell" {
+				cells = append(cells, cell)
+			}
+		}
+
+		node := Node{Type: "layoutSection"}
+		for _, cell := range cells {
+			node.Content = append(node.Content, Node{
+				Type:    "layoutColumn",
+				Attrs:   map[string]any{"width": 100 / float64(len(cells))},
+				Content: fitBlocks("layoutColumn", p.blocks(cell.children, false)),
+			})
+		}
+		sections = append(sections, node)
+	}
+	return sections
+}
+
+// storagePanelTypes maps Confluence's panel macros to ADF panel types.
+// Confluence's note macro is yellow and its warning macro red.
+var storagePanelTypes = map[string]string{
+	"info":    "info",
+	"tip":     "success",
+	"note":    "warning",
+	"warning": "error",
+}
+
+// storageStatusColors maps the colours of the status macro to ADF status
+// colors
+var storageStatusColors = map[string]string{
+	"grey":   "neutral",
+	"red":    "red",
+	"yellow": "yellow",
+	"green":  "green",
+	"blue":   "blue",
+	"purple": "purple",
+}
+
+// macro converts an ac:structured-macro element. Macros with an ADF
+// equivalent become that node, and others become extension nodes.
+func (p *storageParser) macro(e *storageElement, inline bool) []Node {
+	name := e.attrs["ac:name"]
+	params := e.params()
+	var body []*storageElement
+	if rich := e.child("ac:rich-text-body"); rich != nil {
+		body = rich.children
+	}
+
+	switch name {
+	case "code", "noformat":
+		node := Node{Type: "codeBlock"}
+		if language := params["language"]; language != "" {
+			node.Attrs = map[string]any{"language": language}
+		}
+		if text := e.child("ac:plain-text-body").textContent(); text != "" {
+			node.Content = []Node{{Type: "text", Text: text}}
+		}
+		return []Node{node}
+
+	case "info", "tip", "note", "warning", "panel":
+		panel := Node{Type: "panel", Attrs: map[string]any{"panelType": "info"}}
+		if panelType, ok := storagePanelTypes[name]; ok {
+			panel.Attrs["panelType"] = panelType
+		} else if color := params["bgColor"]; color != "" {
+			panel.Attrs["panelType"] = "custom"
+			panel.Attrs["panelColor"] = color
+		}
+
+		// ADF panels have no title, so it leads the content in bold
+		if title := params["title"]; title != "" {
+			panel.Content = append(panel.Content, Node{
+				Type:    "paragraph",
+				Content: []Node{textNode(title, []Mark{{Type: "strong"}})},
+			})
+		}
+		panel.Content = append(panel.Content, fitBlocks("panel", p.blocks(body, false))...)
+		return []Node{panel}
+
+	case "expand":
+		expand := Node{Type: "expand", Content: fitBlocks("expand", p.blocks(body, false))}
+		if title := params["title"]; title != "" {
+			expand.Attrs = map[string]any{"title": title}
+		}
+		return []Node{expand}
+
+	case "status":
+		color, ok := storageStatusColors[strings.ToLower(params["colour"])]
+		if !ok {
+			color = "neutral"
+		}
+		return []Node{{Type: "status", Attrs: map[string]any{"text": params["title"], "color": color}}}
+	}
+
+	macroParams := map[string]any{}
+	for key, value := range params {
+		macroParams[key] = map[string]any{"value": value}
+	}
+	parameters := map[string]any{"macroParams": macroParams}
+	if id := e.attrs["ac:macro-id"]; id != "" {
+		parameters["macroMetadata"] = map[string]any{"macroId": map[string]any{"value": id}}
+	}
+
+	node := Node{
+		Type: "extension",
+		Attrs: map[string]any{
+			"extensionType": "com.atlassian.confluence.macro.core",
+			"extensionKey":  name,
+			"parameters":    parameters,
+		},
+	}
+	switch {
+	case e.child("ac:rich-text-body") != nil:
+		node.Type = "bodiedExtension"
+		node.Content = fitBlocks("bodiedExtension", p.blocks(body, false))
+	case e.child("ac:plain-text-body") != nil:
+		// Keep the text of macros such as html or sql in their body
+		node.Type = "bodiedExtension"
+		node.Content = []Node{{
+			Type:    "codeBlock",
+			Content: []Node{{Type: "text", Text: e.child("ac:plain-text-body").textContent()}},
+		}}
+	case inline:
+		node.Type = "inlineExtension"
+	}
+	return []Node{node}
+}
+
+// Inline storage format syntax
+var (
+	storageSpace      = regexp.MustCompile(`[ \t\r\n]+`)
+	storageColor      = regexp.MustCompile(`(?:^|;)\s*color\s*:\s*([^;]+)`)
+	storageBackground = regexp.MustCompile(`(?:^|;)\s*background-color\s*:\s*([^;]+)`)
+)
+
+// storageEmoticons maps the names of Confluence's legacy emoticons to
+// their emoji
+var storageEmoticons = map[string]string{
+	"smile":        "🙂",
+	"sad":          "🙁",
+	"cheeky":       "😛",
+	"laugh":        "😀",
+	"wink":         "😉",
+	"thumbs-up":    "👍",
+	"thumbs-down":  "👎",
+	"information":  "ℹ️",
+	"tick":         "✅",
+	"cross":        "❌",
+	"warning":      "⚠️",
+	"plus":         "➕",
+	"minus":        "➖",
+	"question":     "❓",
+	"light-on":     "💡",
+	"light-off":    "💡",
+	"yellow-star":  "⭐",
+	"heart":        "❤️",
+	"broken-heart": "💔",
+}
+
+// addStorageMark adds a formatting mark to the marks of the text inside
+// an element. Text inside <code> only keeps its link, as ADF doesn't let
+// the code mark combine with anything else.
+func addStorageMark(marks []Mark, mark Mark) []Mark {
+	if mark.Type != "link" && containsMark(marks, "code") {
+		return marks
+	}
+	return appendMarks(marks, mark)
+}
+
+// inlines converts an inline element or run of text into inline nodes,
+// adding marks to the given outer marks as formatting elements are
+// entered. Images are returned as mediaSingle nodes for the caller to
+// place.
+func (p *storageParser) inlines(e *storageElement, marks []Mark) []Node {
+	if e.name == "" {
+		return []Node{textNode(storageSpace.ReplaceAllString(e.text, " "), marks)}
+	}
+
+	switch e.name {
+	case "strong", "b":
+		marks = addStorageMark(marks, Mark{Type: "strong"})
+	case "em", "i":
+		marks = addStorageMark(marks, Mark{Type: "em"})
+	case "u":
+		marks = addStorageMark(marks, Mark{Type: "underline"})
+	case "s", "del", "strike":
+		marks = addStorageMark(marks, Mark{Type: "strike"})
+	case "code":
+		marks = codeMarks(marks)
+	case "sub", "sup":
+		marks = addStorageMark(marks, Mark{Type: "subsup", Attrs: map[string]any{"type": e.name}})
+	case "a":
+		if href := e.attrs["href"]; href != "" && !hasLink(marks) {
+			marks = addStorageMark(marks, newLinkMark(href, e.attrs["title"]))
+		}
+	case "span":
+		style := e.attrs["style"]
+		if m := storageColor.FindStringSubmatch(style); m != nil {
+			marks = addStorageMark(marks, Mark{Type: "textColor", Attrs: map[string]any{"color": strings.TrimSpace(m[1])}})
+		}
+		if m := storageBackground.FindStringSubmatch(style); m != nil {
+			marks = addStorageMark(marks, Mark{Type: "backgroundColor", Attrs: map[string]any{"color": strings.TrimSpace(m[1])}})
+		}
+	case "br":
+		return []Node{{Type: "hardBreak"}}
+	case "time":
+		return []Node{storageDate(e, marks)}
+	case "ac:link":
+		return p.link(e, marks)
+	case "ac:image":
+		return []Node{p.image(e)}
+	case "ac:emoticon":
+		return []Node{storageEmoticon(e)}
+	case "ac:structured-macro":
+		return p.macro(e, true)
+	case "ac:placeholder", "ac:parameter":
+		return nil
+	}
+
+	var nodes []Node
+	for _, child := range e.children {
+		nodes = append(nodes, p.inlines(child, marks)...)
+	}
+	return nodes
+}
+
+// link converts an ac:link element. Links to users become mentions and
+// links to URLs become link marks. Links to pages, attachments and other
+// content only keep their text, as their URLs aren't known.
+func (p *storageParser) link(e *storageElement, marks []Mark) []Node {
+	if user := e.child("ri:user"); user != nil {
+		id := user.attrs["ri:account-id"]
+		if id == "" {
+			id = user.attrs["ri:userkey"]
+		}
+		return []Node{{Type: "mention", Attrs: map[string]any{"id": id}}}
+	}
+
+	if url := e.child("ri:url"); url != nil && !hasLink(marks) {
+		marks = appendMarks(marks, newLinkMark(url.attrs["ri:value"], ""))
+	}
+
+	if body := e.child("ac:link-body"); body != nil {
+		var nodes []Node
+		for _, child := range body.children {
+			nodes = append(nodes, p.inlines(child, marks)...)
+		}
+		return nodes
+	}
+	if body := e.child("ac:plain-text-link-body"); body != nil {
+		return []Node{textNode(body.textContent(), marks)}
+	}
+
+	// Without a body, the link shows the title of what it links to
+	var title string
+	switch {
+	case e.child("ri:page") != nil:
+		title = e.child("ri:page").attrs["ri:content-title"]
+	case e.child("ri:blog-post") != nil:
+		title = e.child("ri:blog-post").attrs["ri:content-title"]
+	case e.child("ri:attachment") != nil:
+		title = e.child("ri:attachment").attrs["ri:filename"]
+	case e.child("ri:space") != nil:
+		title = e.child("ri:space").attrs["ri:space-key"]
+	case e.child("ri:url") != nil:
+		title = e.child("ri:url").attrs["ri:value"]
+	}
+	if title == "" {
+		title = e.attrs["ac:anchor"]
+	}
+	return []Node{textNode(title, marks)}
+}
+
+// image converts an ac:image element into a mediaSingle node, with its
+// caption if it has one. Attachments have no media ID, so they are
+// identified by their file name, which is also kept in __fileName in case
+// the image has its own alt text.
+func (p *storageParser) image(e *storageElement) Node {
+	attrs := map[string]any{}
+	if attachment := e.child("ri:attachment"); attachment != nil {
+		name := attachment.attrs["ri:filename"]
+		attrs["type"] = "file"
+		attrs["id"] = name
+		attrs["collection"] = p.attachmentCollection(attachment)
+		attrs["alt"] = name
+		attrs["__fileName"] = name
+	} else if url := e.child("ri:url"); url != nil {
+		attrs["type"] = "external"
+		attrs["url"] = url.attrs["ri:value"]
+	}
+	if alt := e.attrs["ac:alt"]; alt != "" {
+		attrs["alt"] = alt
+	}
+	for _, attr := range []string{"width", "height"} {
+		if size, err := strconv.Atoi(e.attrs["ac:"+attr]); err == nil {
+			attrs[attr] = float64(size)
+		}
+	}
+
+	node := Node{
+		Type:    "mediaSingle",
+		Attrs:   map[string]any{"layout": "center"},
+		Content: []Node{{Type: "media", Attrs: attrs}},
+	}
+	if caption := e.child("ac:caption"); caption != nil {
+		node.Content = append(node.Content, Node{Type: "caption", Content: p.inlineContent(caption.children)})
+	}
+	return node
+}
+
+// attachmentCollection returns the collection of an attachment: that of
+// the page given in the options, or of the page named by the attachment's
+// ri:content-entity. Attachments on other pages named by title can't be
+// located, so they have no collection.
+func (p *storageParser) attachmentCollection(attachment *storageElement) string {
+	pageID := p.options.PageID
+	if entity := attachment.child("ri:content-entity"); entity != nil {
+		pageID = entity.attrs["ri:content-id"]
+	} else if attachment.child("ri:page") != nil || attachment.child("ri:blog-post") != nil {
+		pageID = ""
+	}
+
+	if pageID == "" {
+		return ""
+	}
+	return "contentId-" + pageID
+}
+
+// storageEmoticon converts an ac:emoticon element into an emoji node
+func storageEmoticon(e *storageElement) Node {
+	shortName := e.attrs["ac:emoji-shortname"]
+	text := e.attrs["ac:emoji-fallback"]
+	if name := e.attrs["ac:name"]; shortName == "" && name != "" {
+		shortName = ":" + name + ":"
+		if text == "" {
+			text = storageEmoticons[name]
+		}
+	}
+
+	attrs := map[string]any{"shortName": shortName}
+	if text != "" {
+		attrs["text"] = text
+	}
+	if id := e.attrs["ac:emoji-id"]; id != "" {
+		attrs["id"] = id
+	}
+	return Node{Type: "emoji", Attrs: attrs}
+}
+
+// storageDate converts a time element into a date node, or its text if
+// the date can't be read
+func storageDate(e *storageElement, marks []Mark) Node {
+	datetime := e.attrs["datetime"]
+	t, err := time.Parse("2006-01-02", datetime)
+	if err != nil {
+		return textNode(datetime, marks)
+	}
+	return Node{Type: "date", Attrs: map[string]any{"timestamp": strconv.FormatInt(t.UnixMilli(), 10)}}
+}
+
+// trimInline tidies up inline content gathered from storage format:
+// adjacent text is merged, whitespace left by collapsing line breaks in
+// the source is dropped from the start and end and after other spaces, and
+// trailing line breaks are removed
+func trimInline(nodes []Node) []Node {
+	var result []Node
+	space := true
+	for _, node := range mergeTextNodes(nodes) {
+		if node.Type == "text" {
+			if space {
+				node.Text = strings.TrimLeft(node.Text, " ")
+			}
+			if node.Text == "" {
+				continue
+			}
+			space = strings.HasSuffix(node.Text, " ")
+		} else {
+			space = node.Type == "hardBreak"
+		}
+		result = append(result, node)
+	}
+
+	for n := len(result); n > 0; n = len(result) {
+		last := &result[n-1]
+		if last.Type == "text" {
+			last.Text = strings.TrimRight(last.Text, " ")
+			if last.Text != "" {
+				break
+			}
+		} else if last.Type != "hardBreak" {
+			break
+		}
+		result = result[:n-1]
+	}
+	return result
+}
+
+// inlineMedia replaces images within text, which ADF can't place inline,
+// with mediaInline nodes for attachments and links for external images
+func inlineMedia(nodes []Node) []Node {
+	for i, node := range nodes {
+		if node.Type != "mediaSingle" {
+			continue
+		}
+		media := node.Content[0]
+		if media.Attrs["type"] == "external" {
+			url, _ := media.Attrs["url"].(string)
+			text, _ := media.Attrs["alt"].(string)
+			if text == "" {
+				text = url
+			}
+			nodes[i] = textNode(text, []Mark{newLinkMark(url, "")})
+			continue
+		}
+		nodes[i] = Node{Type: "mediaInline", Attrs: media.Attrs}
+	}
+	return nodes
+}
@@ -0,0 +1,182 @@
+package adf2md_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestParseStorage(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Empty document",
+			input:    "",
+			expected: `{"type":"doc","version":1}`,
+		},
+		{
+			name:     "Text formatting",
+			input:    "<h2>Release &amp; notes</h2>\n<p>Some <strong>bold</strong>, <em>em</em> and <code>code</code>,<br/>a <a href=\"https://example.com\">link</a> and <span style=\"color: rgb(255,0,0);\">red</span></p>",
+			expected: `{"type":"doc","version":1,"content":[{"type":"heading","content":[{"type":"text","text":"Release \u0026 notes"}],"attrs":{"level":2}},{"type":"paragraph","content":[{"type":"text","text":"Some "},{"type":"text","text":"bold","marks":[{"type":"strong"}]},{"type":"text","text":", "},{"type":"text","text":"em","marks":[{"type":"em"}]},{"type":"text","text":" and "},{"type":"text","text":"code","marks":[{"type":"code"}]},{"type":"text","text":","},{"type":"hardBreak"},{"type":"text","text":"a "},{"type":"text","text":"link","marks":[{"type":"link","attrs":{"href":"https://example.com"}}]},{"type":"text","text":" and "},{"type":"text","text":"red","marks":[{"type":"textColor","attrs":{"color":"rgb(255,0,0)"}}]}]}]}`,
+		},
+		{
+			name:     "Lists and tables",
+			input:    "<ol start=\"3\"><li>Three<ul><li><p>Nested</p></li></ul></li></ol><table><tbody><tr><th>Name</th><th>Notes</th></tr><tr><td colspan=\"2\">Both</td></tr></tbody></table>",
+			expected: `{"type":"doc","version":1,"content":[{"type":"orderedList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"Three"}]},{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"Nested"}]}]}]}]}],"attrs":{"order":3}},{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Name"}]}]},{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Notes"}]}]}]},{"type":"tableRow","content":[{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"Both"}]}],"attrs":{"colspan":2}}]}]}]}`,
+		},
+		{
+			name:     "Task list",
+			input:    "<ac:task-list><ac:task><ac:task-id>7</ac:task-id><ac:task-status>complete</ac:task-status><ac:task-body>Ship <ac:link><ri:user ri:account-id=\"abc\" /></ac:link></ac:task-body></ac:task><ac:task><ac:task-status>incomplete</ac:task-status><ac:task-body>Announce</ac:task-body></ac:task></ac:task-list>",
+			expected: `{"type":"doc","version":1,"content":[{"type":"taskList","content":[{"type":"taskItem","content":[{"type":"text","text":"Ship "},{"type":"mention","attrs":{"id":"abc"}}],"attrs":{"localId":"7","state":"DONE"}},{"type":"taskItem","content":[{"type":"text","text":"Announce"}],"attrs":{"localId":"task-2","state":"TODO"}}],"attrs":{"localId":"task-list-1"}}]}`,
+		},
+		{
+			name:     "Code macro",
+			input:    "<ac:structured-macro ac:name=\"code\" ac:schema-version=\"1\"><ac:parameter ac:name=\"language\">go</ac:parameter><ac:plain-text-body><![CDATA[if a < b {\n}]]></ac:plain-text-body></ac:structured-macro>",
+			expected: `{"type":"doc","version":1,"content":[{"type":"codeBlock","content":[{"type":"text","text":"if a \u003c b {\n}"}],"attrs":{"language":"go"}}]}`,
+		},
+		{
+			name:     "Panel macros",
+			input:    "<ac:structured-macro ac:name=\"warning\"><ac:parameter ac:name=\"title\">Careful</ac:parameter><ac:rich-text-body><p>Mind the gap</p></ac:rich-text-body></ac:structured-macro><ac:structured-macro ac:name=\"panel\"><ac:parameter ac:name=\"bgColor\">#eae6ff</ac:parameter><ac:rich-text-body><p>Custom</p></ac:rich-text-body></ac:structured-macro>",
+			expected: `{"type":"doc","version":1,"content":[{"type":"panel","content":[{"type":"paragraph","content":[{"type":"text","text":"Careful","marks":[{"type":"strong"}]}]},{"type":"paragraph","content":[{"type":"text","text":"Mind the gap"}]}],"attrs":{"panelType":"error"}},{"type":"panel","content":[{"type":"paragraph","content":[{"type":"text","text":"Custom"}]}],"attrs":{"panelColor":"#eae6ff","panelType":"custom"}}]}`,
+		},
+		{
+			name:     "Status macro and emoticon",
+			input:    "<p>State: <ac:structured-macro ac:name=\"status\"><ac:parameter ac:name=\"colour\">Green</ac:parameter><ac:parameter ac:name=\"title\">DONE</ac:parameter></ac:structured-macro> <ac:emoticon ac:name=\"tick\" /></p>",
+			expected: `{"type":"doc","version":1,"content":[{"type":"paragraph","content":[{"type":"text","text":"State: "},{"type":"status","attrs":{"color":"green","text":"DONE"}},{"type":"text","text":" "},{"type":"emoji","attrs":{"shortName":":tick:","text":"✅"}}]}]}`,
+		},
+		{
+			name:     "Other macros",
+			input:    "<ac:structured-macro ac:name=\"toc\" ac:macro-id=\"m1\"><ac:parameter ac:name=\"maxLevel\">3</ac:parameter></ac:structured-macro><p>See <ac:structured-macro ac:name=\"jira\"><ac:parameter ac:name=\"key\">ABC-1</ac:parameter></ac:structured-macro></p>",
+			expected: `{"type":"doc","version":1,"content":[{"type":"extension","attrs":{"extensionKey":"toc","extensionType":"com.atlassian.confluence.macro.core","parameters":{"macroMetadata":{"macroId":{"value":"m1"}},"macroParams":{"maxLevel":{"value":"3"}}}}},{"type":"paragraph","content":[{"type":"text","text":"See "},{"type":"inlineExtension","attrs":{"extensionKey":"jira","extensionType":"com.atlassian.confluence.macro.core","parameters":{"macroParams":{"key":{"value":"ABC-1"}}}}}]}]}`,
+		},
+		{
+			name:     "Images",
+			input:    "<p><ac:image ac:width=\"640\"><ri:attachment ri:filename=\"diagram.png\" /></ac:image></p><p>Logo: <ac:image><ri:url ri:value=\"https://example.com/logo.png\" /></ac:image></p>",
+			expected: `{"type":"doc","version":1,"content":[{"type":"mediaSingle","content":[{"type":"media","attrs":{"__fileName":"diagram.png","alt":"diagram.png","collection":"","id":"diagram.png","type":"file","width":640}}],"attrs":{"layout":"center"}},{"type":"paragraph","content":[{"type":"text","text":"Logo: "},{"type":"text","text":"https://example.com/logo.png","marks":[{"type":"link","attrs":{"href":"https://example.com/logo.png"}}]}]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := adf2md.ParseStorage(tt.input)
+			if err != nil {
+				t.Fatalf("ParseStorage failed: %v", err)
+			}
+
+			result, err := json.Marshal(node)
+			if err != nil {
+				t.Fatalf("Failed to encode ADF: %v", err)
+			}
+
+			if string(result) != tt.expected {
+				t.Errorf("\nExpected: %s\nGot:      %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseStorageAttachments(t *testing.T) {
+	input := `<ac:image ac:alt="Architecture"><ri:attachment ri:filename="diagram one.png" /></ac:image>` +
+		`<ac:image><ri:attachment ri:filename="shared.png"><ri:content-entity ri:content-id="555" /></ri:attachment></ac:image>` +
+		`<ac:image><ri:attachment ri:filename="elsewhere.png"><ri:page ri:content-title="Other page" /></ri:attachment></ac:image>`
+
+	tests := []struct {
+		name     string
+		options  adf2md.RenderOptions
+		expected string
+	}{
+		{
+			name:     "Without a resolver",
+			options:  adf2md.RenderOptions{},
+			expected: "![Architecture](</wiki/download/attachments/contentId-98765/diagram one.png>)\n\n![shared.png](/wiki/download/attachments/contentId-555/shared.png)\n\n[Image: elsewhere.png - Type: file]\n\n",
+		},
+		{
+			name:     "Confluence Cloud",
+			options:  adf2md.RenderOptions{MediaResolver: adf2md.ConfluenceMediaResolver("https://example.atlassian.net")},
+			expected: "![Architecture](https://example.atlassian.net/wiki/download/attachments/98765/diagram%20one.png)\n\n![shared.png](https://example.atlassian.net/wiki/download/attachments/555/shared.png)\n\n[Image: elsewhere.png - Type: file]\n\n",
+		},
+	}
+
+	node, err := adf2md.ParseStorageWithOptions(input, adf2md.StorageOptions{PageID: "98765"})
+	if err != nil {
+		t.Fatalf("ParseStorageWithOptions failed: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := adf2md.NewRenderer().WithOptions(tt.options).RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseStorageValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Formatting in code",
+			input:    `<p><code>a <b>b</b> <a href="https://example.com">c</a></code></p>`,
+			expected: "`a b `[`c`](https://example.com)\n\n",
+		},
+		{
+			name:     "Task list in a list item",
+			input:    `<ul><li><ac:task-list><ac:task><ac:task-status>incomplete</ac:task-status><ac:task-body>Ship</ac:task-body></ac:task></ac:task-list></li></ul>`,
+			expected: "* - [ ] Ship\n",
+		},
+		{
+			name:     "Expand in an expand",
+			input:    `<ac:structured-macro ac:name="expand"><ac:parameter ac:name="title">Outer</ac:parameter><ac:rich-text-body><ac:structured-macro ac:name="expand"><ac:parameter ac:name="title">Inner</ac:parameter><ac:rich-text-body><ac:structured-macro ac:name="expand"><ac:parameter ac:name="title">Innermost</ac:parameter><ac:rich-text-body><p>Deep</p></ac:rich-text-body></ac:structured-macro></ac:rich-text-body></ac:structured-macro></ac:rich-text-body></ac:structured-macro>`,
+			expected: "<details>\n<summary>Outer</summary>\n\n<details>\n<summary>Inner</summary>\n\n**Innermost**\n\nDeep\n\n</details>\n\n</details>\n\n",
+		},
+		{
+			name:     "Panel in a panel",
+			input:    `<ac:structured-macro ac:name="info"><ac:rich-text-body><p>Outer</p><ac:structured-macro ac:name="note"><ac:rich-text-body><p>Inner</p></ac:rich-text-body></ac:structured-macro></ac:rich-text-body></ac:structured-macro>`,
+			expected: "> **Panel (info)**\n> Outer\n>\n> Inner\n\n",
+		},
+		{
+			name:     "Table in a table cell",
+			input:    `<table><tr><td><p>Outer</p><table><tr><td>a</td><td>b</td></tr></table></td></tr></table>`,
+			expected: "|  |\n| --- |\n| Outer<br>a<br>b |\n\n",
+		},
+		{
+			name:     "Headings and macros in a list item",
+			input:    `<ol><li><h3>Step</h3><ac:structured-macro ac:name="toc" /><blockquote><h4>Quoted</h4></blockquote></li></ol>`,
+			expected: "1. Step\n  [Macro: toc]\n  Quoted\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := adf2md.ParseStorage(tt.input)
+			if err != nil {
+				t.Fatalf("ParseStorage failed: %v", err)
+			}
+
+			if errs := adf2md.Validate(node); len(errs) > 0 {
+				t.Errorf("Invalid ADF: %v", errs)
+			}
+
+			result, err := adf2md.NewRenderer().RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
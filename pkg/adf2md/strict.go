@@ -0,0 +1,70 @@
+package adf2md
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Unsupported describes a node or mark that the renderer has no way of
+// representing, so its content or formatting is lost from the output
+type Unsupported struct {
+	// Either "node" or "mark"
+	Kind string
+	// The ADF type of the node or mark
+	Type string
+	// Where it appears in the document, as a JSON pointer such as
+	// "/content/2/content/0/marks/1"
+	Path string
+}
+
+// String describes the unsupported node or mark and where it is
+func (u Unsupported) String() string {
+	return u.Kind + " " + strconv.Quote(u.Type) + " at " + location(u.Path)
+}
+
+// location describes a JSON pointer into a document for a message
+func location(path string) string {
+	if path == "" {
+		return "the document root"
+	}
+	return path
+}
+
+// UnsupportedError is returned in strict mode when a document contains
+// nodes or marks that the renderer doesn't support
+type UnsupportedError struct {
+	// Every unsupported node and mark, in document order
+	Unsupported []Unsupported
+}
+
+// Error lists the unsupported nodes and marks
+func (e *UnsupportedError) Error() string {
+	items := make([]string, len(e.Unsupported))
+	for i, u := range e.Unsupported {
+		items[i] = u.String()
+	}
+	return "unsupported ADF content: " + strings.Join(items, ", ")
+}
+
+// unsupported returns the warnings about unknown nodes and marks
+func unsupported(warnings []Warning) []Unsupported {
+	var result []Unsupported
+	for _, warning := range warnings {
+		switch warning.Kind {
+		case WarningUnknownNode:
+			result = append(result, Unsupported{Kind: "node", Type: warning.Type, Path: warning.Path})
+		case WarningUnknownMark:
+			result = append(result, Unsupported{Kind: "mark", Type: warning.Type, Path: warning.Path})
+		}
+	}
+	return result
+}
+
+// supportsMark reports whether there is a renderer for a mark type
+func (r *Renderer) supportsMark(markType string) bool {
+	if _, ok := r.markRenderers[markType]; ok {
+		return true
+	}
+	_, ok := r.builtinMark(markType)
+	return ok
+}
@@ -0,0 +1,121 @@
+package adf2md_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestStrict(t *testing.T) {
+	input := `{"version":1,"type":"doc","content":[
+		{"type":"paragraph","content":[{"type":"text","text":"fine","marks":[{"type":"strong"}]}]},
+		{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[
+			{"type":"text","text":"noted","marks":[{"type":"em"},{"type":"annotation","attrs":{"id":"a1"}}]}
+		]}]}]},
+		{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableCell","content":[
+			{"type":"paragraph","content":[{"type":"placeholder","attrs":{"text":"Type here"}}]}
+		]}]}]},
+		{"type":"futureBlock"}
+	]}`
+
+	node, err := adf2md.ParseADF(input)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		renderer *adf2md.Renderer
+		expected []adf2md.Unsupported
+	}{
+		{
+			name:     "Lenient by default",
+			renderer: adf2md.NewRenderer(),
+		},
+		{
+			name:     "Strict lists every unsupported node and mark",
+			renderer: adf2md.NewRenderer().WithOptions(adf2md.RenderOptions{ListIndent: 2, Strict: true}),
+			expected: []adf2md.Unsupported{
+				{Kind: "mark", Type: "annotation", Path: "/content/1/content/0/content/0/content/0/marks/1"},
+				{Kind: "node", Type: "placeholder", Path: "/content/2/content/0/content/0/content/0/content/0"},
+				{Kind: "node", Type: "futureBlock", Path: "/content/3"},
+			},
+		},
+		{
+			name: "Registered renderers count as supported",
+			renderer: adf2md.NewRenderer().WithOptions(adf2md.RenderOptions{ListIndent: 2, Strict: true}).
+				RegisterMark("annotation", adf2md.PlainMarkRenderer).
+				Register("futureBlock", adf2md.NodeRendererFunc(func(ctx *adf2md.RenderContext, node *adf2md.Node) error {
+					return nil
+				})),
+			expected: []adf2md.Unsupported{
+				{Kind: "node", Type: "placeholder", Path: "/content/2/content/0/content/0/content/0/content/0"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.renderer.RenderToMarkdown(node)
+			streamErr := tt.renderer.Render(io.Discard, node)
+
+			if tt.expected == nil {
+				if err != nil || streamErr != nil {
+					t.Fatalf("Unexpected errors: %v, %v", err, streamErr)
+				}
+				return
+			}
+
+			var unsupported *adf2md.UnsupportedError
+			if !errors.As(err, &unsupported) {
+				t.Fatalf("RenderToMarkdown() error = %v, want *UnsupportedError", err)
+			}
+			if !reflect.DeepEqual(unsupported.Unsupported, tt.expected) {
+				t.Errorf("\nExpected: %+v\nGot:      %+v", tt.expected, unsupported.Unsupported)
+			}
+
+			if streamErr == nil || streamErr.Error() != err.Error() {
+				t.Errorf("Render() error = %v, want %v", streamErr, err)
+			}
+		})
+	}
+}
+
+func TestStrictRenderWritesNothing(t *testing.T) {
+	// Far more output than Render buffers before writing, followed by a
+	// node that can't be rendered
+	doc := &adf2md.Node{Type: "doc", Version: 1}
+	for i := 0; i < 500; i++ {
+		doc.Content = append(doc.Content, adf2md.Node{Type: "paragraph", Content: []adf2md.Node{
+			{Type: "text", Text: "Lorem ipsum dolor sit amet, consectetur adipiscing elit"},
+		}})
+	}
+	doc.Content = append(doc.Content, adf2md.Node{Type: "futureBlock"})
+
+	renderer := adf2md.NewRenderer().WithOptions(adf2md.RenderOptions{ListIndent: 2, Strict: true})
+
+	var out strings.Builder
+	err := renderer.Render(&out, doc)
+
+	var unsupported *adf2md.UnsupportedError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("Render() error = %v, want *UnsupportedError", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Render() wrote %d bytes before failing, want none", out.Len())
+	}
+
+	// Without the unsupported node everything is written
+	doc.Content = doc.Content[:len(doc.Content)-1]
+	out.Reset()
+	if err := renderer.Render(&out, doc); err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if expected, _ := renderer.RenderToMarkdown(doc); out.String() != expected {
+		t.Errorf("Render() wrote %d bytes, want the %d bytes of RenderToMarkdown", out.Len(), len(expected))
+	}
+}
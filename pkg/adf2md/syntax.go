@@ -0,0 +1,74 @@
+package adf2md
+
+// syntax holds the built-in rendering of an output format other than
+// Markdown. These formats share the Markdown renderer's traversal, writer,
+// custom renderers, extension handlers and warnings, and only replace what
+// is written for each node and mark.
+type syntax struct {
+	// Renderers replacing the built-in Markdown ones. Nodes without an
+	// entry, such as doc and paragraph, are rendered as they are for
+	// Markdown.
+	nodes map[string]func(r *Renderer, w *markdownWriter, node *Node)
+
+	// Renderers for each mark type, used instead of builtinMarkRenderers
+	marks map[string]MarkRenderer
+
+	// Escapes characters in text that would be read as markup
+	escape func(text string) string
+
+	// Whether text in code spans is escaped too, for formats that read
+	// markup inside them
+	escapeCode bool
+
+	// Escapes characters that would be read as markup at the start of a
+	// line, if the text starts one or contains line breaks
+	escapeLineStarts func(text string, atLineStart bool) string
+
+	// Formats a placeholder for content that can't be rendered, such as
+	// an unsupported node
+	placeholder func(text string) string
+}
+
+// builtinMark returns the built-in renderer for a mark type in the
+// renderer's output format
+func (r *Renderer) builtinMark(markType string) (MarkRenderer, bool) {
+	if r.syntax != nil {
+		markRenderer, ok := r.syntax.marks[markType]
+		return markRenderer, ok
+	}
+	markRenderer, ok := builtinMarkRenderers[markType]
+	return markRenderer, ok
+}
+
+// escapeText escapes characters in text that would be read as markup in
+// the renderer's output format. ctx only matters for Markdown.
+func (r *Renderer) escapeText(text string, ctx escapeContext) string {
+	if r.syntax != nil {
+		return r.syntax.escape(text)
+	}
+	return escapeText(text, ctx)
+}
+
+// escapesCode reports whether text in code spans has to be escaped in the
+// renderer's output format. Markdown code spans are literal.
+func (r *Renderer) escapesCode() bool {
+	return r.syntax != nil && r.syntax.escapeCode
+}
+
+// escapeLineStarts escapes markup at the start of lines in the renderer's
+// output format
+func (r *Renderer) escapeLineStarts(text string, atLineStart bool) string {
+	if r.syntax != nil {
+		return r.syntax.escapeLineStarts(text, atLineStart)
+	}
+	return escapeLineStarts(text, atLineStart)
+}
+
+// placeholder formats a placeholder such as "[Unsupported ADF Element: x]"
+// in the renderer's output format
+func (r *Renderer) placeholder(text string) string {
+	if r.syntax != nil {
+		return r.syntax.placeholder(text)
+	}
+	return "[" + text + "]"
+}
@@ -0,0 +1,320 @@
+package adf2md
+
+import (
+	"strconv"
+	"strings"
+)
+
+// TableMode controls how table nodes are rendered
+type TableMode int
+
+const (
+	// TableModeAuto renders pipe tables, falling back to HTML for tables
+	// that a pipe table can't express (merged cells or block content)
+	TableModeAuto TableMode = iota
+	// TableModeGFM always renders pipe tables, flattening anything that
+	// doesn't fit onto a single line
+	TableModeGFM
+	// TableModeHTML always renders HTML tables
+	TableModeHTML
+)
+
+// renderTable renders a table node according to the configured TableMode
+func (r *Renderer) renderTable(w *markdownWriter, node *Node) {
+	switch r.options.TableMode {
+	case TableModeHTML:
+		r.renderHTMLTable(w, node)
+	case TableModeGFM:
+		r.renderPipeTable(w, node)
+	default:
+		if isComplexTable(node) {
+			r.renderHTMLTable(w, node)
+		} else {
+			r.renderPipeTable(w, node)
+		}
+	}
+}
+
+// cellLayout is how table cells and layout columns are written, which
+// depends on the table or layout around them
+type cellLayout int
+
+const (
+	// cellsDefault flattens table cells onto one line, as in a pipe table,
+	// and writes layout columns one after another
+	cellsDefault cellLayout = iota
+	// cellsPipe flattens layout columns onto one line, as pipe table cells
+	cellsPipe
+	// cellsHTML writes table cells and layout columns as HTML <th> and
+	// <td> elements
+	cellsHTML
+	// cellsFlex writes layout columns as <div> elements in a flex container
+	cellsFlex
+)
+
+// renderPipeTable renders a table node as a GitHub-Flavored Markdown pipe table
+func (r *Renderer) renderPipeTable(w *markdownWriter, node *Node) {
+	rows := tableRows(node)
+	if len(rows) == 0 {
+		return
+	}
+
+	columns := tableColumnCount(rows)
+	if columns == 0 {
+		return
+	}
+
+	outer := w.state.cells
+	w.state.cells = cellsDefault
+	defer func() { w.state.cells = outer }()
+
+	// GFM tables always need a header row. If the first row isn't made of
+	// tableHeader cells, emit an empty header so no content is lost.
+	body := rows
+	if isHeaderRow(rows[0]) {
+		r.renderPaddedRow(w, rows[0], columns)
+		body = rows[1:]
+	} else {
+		w.WriteString("|" + strings.Repeat("  |", columns))
+	}
+
+	w.ensureNewlines(1)
+	w.WriteString("|" + strings.Repeat(" --- |", columns))
+
+	for i := range body {
+		w.ensureNewlines(1)
+		r.renderPaddedRow(w, body[i], columns)
+	}
+
+	w.ensureNewlines(2)
+}
+
+// renderPaddedRow renders a row of a pipe table, which the built-in
+// tableRow renderer pads with empty cells up to the given number of
+// columns
+func (r *Renderer) renderPaddedRow(w *markdownWriter, row *Node, columns int) {
+	outer := w.state.tableColumns
+	w.state.tableColumns = columns
+	r.renderNode(w, row)
+	w.state.tableColumns = outer
+}
+
+// renderTableRow renders a tableRow node, as a row of an HTML table or as
+// a single pipe table line padded with empty cells to the width of its
+// table
+func (r *Renderer) renderTableRow(w *markdownWriter, node *Node) {
+	if w.state.cells == cellsHTML {
+		r.renderHTMLTableRow(w, node)
+		return
+	}
+
+	var result strings.Builder
+	result.WriteString("|")
+
+	for i := range node.Content {
+		result.WriteString(" " + r.renderToString(w, &node.Content[i]) + " |")
+	}
+	for i := len(node.Content); i < w.state.tableColumns; i++ {
+		result.WriteString("  |")
+	}
+
+	w.WriteString(result.String())
+	w.ensureNewlines(1)
+}
+
+// renderTableCell renders a tableHeader or tableCell node, as an HTML
+// element or flattened onto a single line so it fits inside a pipe table
+func (r *Renderer) renderTableCell(w *markdownWriter, node *Node) {
+	if w.state.cells == cellsHTML {
+		tag := "td"
+		if node.Type == "tableHeader" {
+			tag = "th"
+		}
+		r.renderHTMLBlock(w, tag, htmlCellAttrs(node), node.Content)
+		return
+	}
+
+	if isMergedCell(node) {
+		w.state.warn(WarningLossy, "merged cell split in a pipe table")
+	}
+	if hasBlockContent(node) {
+		w.state.warn(WarningLossy, "block content flattened onto one line of a pipe table")
+	}
+	w.WriteString(r.renderFlattened(w, node))
+}
+
+// renderFlattened renders the content of a table cell or layout column
+// flattened onto a single line so it fits inside a pipe table
+func (r *Renderer) renderFlattened(w *markdownWriter, node *Node) string {
+	content := r.renderBlocks(w, node.Content)
+	if content == "" {
+		return ""
+	}
+
+	// Hard breaks, paragraph spacing and any remaining newlines all become
+	// <br> since a pipe table row can't span multiple lines
+	lines := strings.Split(content, "\n")
+	flattened := lines[:0]
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			flattened = append(flattened, line)
+		}
+	}
+	content = strings.Join(flattened, "<br>")
+
+	// Unescaped pipes would split the cell
+	return escapeTableCell(content)
+}
+
+// tableRows returns the tableRow children of a table node
+func tableRows(node *Node) []*Node {
+	var rows []*Node
+	for i := range node.Content {
+		if node.Content[i].Type == "tableRow" {
+			rows = append(rows, &node.Content[i])
+		}
+	}
+	return rows
+}
+
+// tableColumnCount returns the number of columns needed to hold the widest row
+func tableColumnCount(rows []*Node) int {
+	columns := 0
+	for _, row := range rows {
+		if len(row.Content) > columns {
+			columns = len(row.Content)
+		}
+	}
+	return columns
+}
+
+// isHeaderRow reports whether every cell in a row is a tableHeader
+func isHeaderRow(row *Node) bool {
+	if len(row.Content) == 0 {
+		return false
+	}
+	for _, cell := range row.Content {
+		if cell.Type != "tableHeader" {
+			return false
+		}
+	}
+	return true
+}
+
+// isComplexTable reports whether a table uses merged cells or block content
+// that can't be represented in a pipe table
+func isComplexTable(node *Node) bool {
+	for _, row := range tableRows(node) {
+		for i := range row.Content {
+			if isMergedCell(&row.Content[i]) || hasBlockContent(&row.Content[i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isMergedCell reports whether a cell spans several columns or rows
+func isMergedCell(cell *Node) bool {
+	if span, ok := intAttr(cell.Attrs, "colspan"); ok && span > 1 {
+		return true
+	}
+	span, ok := intAttr(cell.Attrs, "rowspan")
+	return ok && span > 1
+}
+
+// hasBlockContent reports whether a cell holds anything but paragraphs
+func hasBlockContent(cell *Node) bool {
+	for _, child := range cell.Content {
+		if child.Type != "paragraph" {
+			return true
+		}
+	}
+	return false
+}
+
+// renderHTMLTable renders a table node as an HTML table, keeping cell spans
+// and widths
+func (r *Renderer) renderHTMLTable(w *markdownWriter, node *Node) {
+	rows := tableRows(node)
+	if len(rows) == 0 {
+		return
+	}
+
+	outer := w.state.cells
+	w.state.cells = cellsHTML
+	defer func() { w.state.cells = outer }()
+
+	w.WriteString("<table>")
+	for _, row := range rows {
+		w.ensureNewlines(1)
+		r.renderNode(w, row)
+	}
+	w.ensureNewlines(1)
+	w.WriteString("</table>")
+	w.ensureNewlines(2)
+}
+
+// renderHTMLTableRow renders a table row as an HTML <tr> element
+func (r *Renderer) renderHTMLTableRow(w *markdownWriter, node *Node) {
+	w.WriteString("<tr>")
+	for i := range node.Content {
+		w.ensureNewlines(1)
+		r.renderNode(w, &node.Content[i])
+	}
+	w.ensureNewlines(1)
+	w.WriteString("</tr>")
+}
+
+// renderHTMLBlock renders content as Markdown inside an HTML element. The
+// content is surrounded by blank lines so that Markdown processors still
+// format it inside the HTML block.
+func (r *Renderer) renderHTMLBlock(w *markdownWriter, tag, attrs string, content []Node) {
+	w.WriteString("<" + tag + attrs + ">")
+
+	written := w.written
+	w.ensureNewlines(2)
+	r.renderContent(w, content)
+	if w.written == written {
+		w.trimNewlines(0)
+	} else {
+		w.ensureNewlines(2)
+	}
+	w.WriteString("</" + tag + ">")
+}
+
+// htmlCellAttrs returns the HTML attributes for a table cell's spans and width
+func htmlCellAttrs(cell *Node) string {
+	var attrs strings.Builder
+
+	if span, ok := intAttr(cell.Attrs, "colspan"); ok && span > 1 {
+		attrs.WriteString(` colspan="` + strconv.Itoa(span) + `"`)
+	}
+	if span, ok := intAttr(cell.Attrs, "rowspan"); ok && span > 1 {
+		attrs.WriteString(` rowspan="` + strconv.Itoa(span) + `"`)
+	}
+
+	// colwidth holds one pixel width per column the cell spans
+	if widths, ok := cell.Attrs["colwidth"].([]any); ok {
+		total := 0
+		for _, w := range widths {
+			if width, ok := w.(float64); ok {
+				total += int(width)
+			}
+		}
+		if total > 0 {
+			attrs.WriteString(` width="` + strconv.Itoa(total) + `"`)
+		}
+	}
+
+	return attrs.String()
+}
+
+// intAttr returns a numeric attribute as an int. JSON numbers decode as
+// float64, so that's the only type accepted.
+func intAttr(attrs map[string]any, key string) (int, bool) {
+	if value, ok := attrs[key].(float64); ok {
+		return int(value), true
+	}
+	return 0, false
+}
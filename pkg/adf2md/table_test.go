@@ -0,0 +1,133 @@
+package adf2md_test
+
+import (
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestRenderTable(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Table with header row",
+			input:    `{"version":1,"type":"doc","content":[{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Name"}]}]},{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Value"}]}]}]},{"type":"tableRow","content":[{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"a"}]}]},{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"1"}]}]}]}]}]}`,
+			expected: "| Name | Value |\n| --- | --- |\n| a | 1 |\n\n",
+		},
+		{
+			name:     "Table without header row",
+			input:    `{"version":1,"type":"doc","content":[{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"a"}]}]},{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"b"}]}]}]}]}]}`,
+			expected: "|  |  |\n| --- | --- |\n| a | b |\n\n",
+		},
+		{
+			name:     "Pipes are escaped",
+			input:    `{"version":1,"type":"doc","content":[{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"a|b"}]}]}]}]}]}`,
+			expected: "| a\\|b |\n| --- |\n\n",
+		},
+		{
+			name:     "Multiple paragraphs and hard breaks become br",
+			input:    `{"version":1,"type":"doc","content":[{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"H"}]}]}]},{"type":"tableRow","content":[{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"one"},{"type":"hardBreak"},{"type":"text","text":"two"}]},{"type":"paragraph","content":[{"type":"text","text":"three"}]}]}]}]}]}`,
+			expected: "| H |\n| --- |\n| one<br>two<br>three |\n\n",
+		},
+		{
+			name:     "Short rows are padded",
+			input:    `{"version":1,"type":"doc","content":[{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"A"}]}]},{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"B"}]}]}]},{"type":"tableRow","content":[{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"x"}]}]}]}]}]}`,
+			expected: "| A | B |\n| --- | --- |\n| x |  |\n\n",
+		},
+		{
+			name:     "Empty cells",
+			input:    `{"version":1,"type":"doc","content":[{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableHeader","content":[]},{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"B"}]}]}]}]}]}`,
+			expected: "|  | B |\n| --- | --- |\n\n",
+		},
+	}
+
+	renderer := adf2md.NewRenderer()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := adf2md.ParseADF(tt.input)
+			if err != nil {
+				t.Fatalf("Failed to parse ADF: %v", err)
+			}
+
+			result, err := renderer.RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRenderTableModes(t *testing.T) {
+	simple := `{"version":1,"type":"doc","content":[{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"A"}]}]}]},{"type":"tableRow","content":[{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"x"}]}]}]}]}]}`
+	merged := `{"version":1,"type":"doc","content":[{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableHeader","attrs":{"colspan":2,"colwidth":[100,50]},"content":[{"type":"paragraph","content":[{"type":"text","text":"Release"}]}]}]},{"type":"tableRow","content":[{"type":"tableCell","attrs":{"rowspan":2},"content":[{"type":"paragraph","content":[{"type":"text","text":"x"}]}]},{"type":"tableCell","content":[]}]}]}]}`
+	blocks := `{"version":1,"type":"doc","content":[{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableCell","content":[{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"one"}]}]}]}]}]}]}]}`
+
+	tests := []struct {
+		name     string
+		mode     adf2md.TableMode
+		input    string
+		expected string
+	}{
+		{
+			name:     "Auto keeps simple tables as pipe tables",
+			mode:     adf2md.TableModeAuto,
+			input:    simple,
+			expected: "| A |\n| --- |\n| x |\n\n",
+		},
+		{
+			name:     "Auto falls back to HTML for merged cells",
+			mode:     adf2md.TableModeAuto,
+			input:    merged,
+			expected: "<table>\n<tr>\n<th colspan=\"2\" width=\"150\">\n\nRelease\n\n</th>\n</tr>\n<tr>\n<td rowspan=\"2\">\n\nx\n\n</td>\n<td></td>\n</tr>\n</table>\n\n",
+		},
+		{
+			name:     "Auto falls back to HTML for block content",
+			mode:     adf2md.TableModeAuto,
+			input:    blocks,
+			expected: "<table>\n<tr>\n<td>\n\n* one\n\n</td>\n</tr>\n</table>\n\n",
+		},
+		{
+			name:     "HTML mode renders simple tables as HTML",
+			mode:     adf2md.TableModeHTML,
+			input:    simple,
+			expected: "<table>\n<tr>\n<th>\n\nA\n\n</th>\n</tr>\n<tr>\n<td>\n\nx\n\n</td>\n</tr>\n</table>\n\n",
+		},
+		{
+			name:     "GFM mode flattens merged cells",
+			mode:     adf2md.TableModeGFM,
+			input:    merged,
+			expected: "| Release |  |\n| --- | --- |\n| x |  |\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := adf2md.ParseADF(tt.input)
+			if err != nil {
+				t.Fatalf("Failed to parse ADF: %v", err)
+			}
+
+			renderer := adf2md.NewRenderer().WithOptions(adf2md.RenderOptions{
+				ListIndent: 2,
+				TableMode:  tt.mode,
+			})
+
+			result, err := renderer.RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,535 @@
+package adf2md
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ValidationError describes a place where a document breaks the ADF schema
+type ValidationError struct {
+	// Where the problem is, as a JSON pointer such as "/content/3/content/0"
+	Path string
+	// What is wrong
+	Message string
+}
+
+// Error describes the problem and where it is
+func (e ValidationError) Error() string {
+	return e.Message + " at " + location(e.Path)
+}
+
+// nodeSpec describes the content model of a node type
+type nodeSpec struct {
+	// Node types allowed as children. Nil means the node has no content.
+	content []string
+	// Whether the node needs at least one child
+	nonEmpty bool
+	// Attributes that must be present
+	required []string
+	// Allowed values of attributes that take one of a fixed set of strings
+	enums map[string][]string
+	// Mark types allowed on the node
+	marks []string
+	// Additional checks that don't fit the fields above
+	check func(v *validator, node *Node, path string)
+}
+
+// markSpec describes the attributes of a mark type
+type markSpec struct {
+	// Attributes that must be present
+	required []string
+	// Allowed values of attributes that take one of a fixed set of strings
+	enums map[string][]string
+}
+
+// Content groups shared by several node types
+var (
+	inlineNodes = []string{
+		"text", "hardBreak", "mention", "emoji", "date", "status",
+		"inlineCard", "inlineExtension", "placeholder", "mediaInline",
+	}
+
+	// Blocks allowed in nested containers such as table cells
+	nestedBlocks = []string{
+		"paragraph", "heading", "bulletList", "orderedList", "taskList",
+		"decisionList", "codeBlock", "blockquote", "panel", "rule",
+		"mediaGroup", "mediaSingle", "blockCard", "embedCard", "extension",
+		"nestedExpand",
+	}
+
+	// Blocks allowed at the top level of a document, apart from layouts
+	topBlocks = extend(without(nestedBlocks, "nestedExpand"),
+		"table", "expand", "bodiedExtension", "multiBodiedExtension")
+
+	// Marks allowed on text, apart from code formatting
+	textMarks = []string{
+		"link", "em", "strong", "strike", "subsup", "underline",
+		"textColor", "backgroundColor", "code", "annotation",
+	}
+
+	// Marks allowed on the inline nodes other than text
+	inlineNodeMarks = []string{"annotation"}
+
+	// Marks allowed on extensions
+	extensionMarks = []string{"dataConsumer", "fragment"}
+
+	// Attributes required on every kind of extension
+	extensionAttrs = []string{"extensionKey", "extensionType"}
+)
+
+// nodeSpecs is the ADF content model for each node type
+var nodeSpecs = map[string]nodeSpec{
+	"doc": {
+		content: extend(topBlocks, "layoutSection"),
+		check:   checkDoc,
+	},
+	"paragraph": {
+		content: inlineNodes,
+		marks:   []string{"alignment", "indentation"},
+	},
+	"text": {
+		marks: textMarks,
+		check: checkText,
+	},
+	"heading": {
+		content:  inlineNodes,
+		required: []string{"level"},
+		marks:    []string{"alignment", "indentation"},
+		check:    checkHeading,
+	},
+	"bulletList": {
+		content:  []string{"listItem"},
+		nonEmpty: true,
+	},
+	"orderedList": {
+		content:  []string{"listItem"},
+		nonEmpty: true,
+		check:    checkOrderedList,
+	},
+	"listItem": {
+		content:  []string{"paragraph", "bulletList", "orderedList", "taskList", "codeBlock", "mediaSingle"},
+		nonEmpty: true,
+		check:    checkListItem,
+	},
+	"taskList": {
+		content:  []string{"taskItem", "taskList"},
+		nonEmpty: true,
+		required: []string{"localId"},
+		check:    checkTaskList,
+	},
+	"taskItem": {
+		content:  inlineNodes,
+		required: []string{"localId", "state"},
+		enums:    map[string][]string{"state": {"TODO", "DONE"}},
+	},
+	"decisionList": {
+		content:  []string{"decisionItem"},
+		nonEmpty: true,
+		required: []string{"localId"},
+	},
+	"decisionItem": {
+		content:  inlineNodes,
+		required: []string{"localId", "state"},
+		enums:    map[string][]string{"state": {"DECIDED", "UNDECIDED"}},
+	},
+	"codeBlock": {
+		content: []string{"text"},
+		marks:   []string{"breakout"},
+		check:   checkCodeBlock,
+	},
+	"blockquote": {
+		content:  []string{"paragraph", "bulletList", "orderedList", "codeBlock", "mediaSingle", "mediaGroup", "extension"},
+		nonEmpty: true,
+	},
+	"panel": {
+		content: []string{
+			"paragraph", "heading", "bulletList", "orderedList", "taskList",
+			"decisionList", "codeBlock", "rule", "mediaGroup", "mediaSingle",
+			"blockCard", "extension",
+		},
+		nonEmpty: true,
+		required: []string{"panelType"},
+		enums:    map[string][]string{"panelType": {"info", "note", "tip", "warning", "error", "success", "custom"}},
+	},
+	"rule":      {},
+	"hardBreak": {marks: inlineNodeMarks},
+	"mention": {
+		required: []string{"id"},
+		marks:    inlineNodeMarks,
+	},
+	"emoji": {
+		required: []string{"shortName"},
+		marks:    inlineNodeMarks,
+	},
+	"date": {
+		required: []string{"timestamp"},
+		marks:    inlineNodeMarks,
+	},
+	"status": {
+		required: []string{"text", "color"},
+		enums:    map[string][]string{"color": {"neutral", "purple", "blue", "red", "yellow", "green"}},
+		marks:    inlineNodeMarks,
+	},
+	"placeholder": {
+		required: []string{"text"},
+	},
+	"inlineCard": {
+		marks: inlineNodeMarks,
+		check: checkCard,
+	},
+	"blockCard": {
+		check: checkCard,
+	},
+	"embedCard": {
+		required: []string{"url", "layout"},
+	},
+	"mediaSingle": {
+		content:  []string{"media", "caption"},
+		nonEmpty: true,
+		marks:    []string{"link"},
+		check:    checkMediaSingle,
+	},
+	"mediaGroup": {
+		content:  []string{"media"},
+		nonEmpty: true,
+	},
+	"media": {
+		required: []string{"type"},
+		enums:    map[string][]string{"type": {"file", "link", "external"}},
+		marks:    []string{"link", "annotation", "border"},
+		check:    checkMedia,
+	},
+	"mediaInline": {
+		required: []string{"id", "collection"},
+		marks:    []string{"link", "annotation", "border"},
+	},
+	"caption": {
+		content: inlineNodes,
+	},
+	"table": {
+		content:  []string{"tableRow"},
+		nonEmpty: true,
+	},
+	"tableRow": {
+		content:  []string{"tableHeader", "tableCell"},
+		nonEmpty: true,
+	},
+	"tableHeader": {
+		content:  without(nestedBlocks, "nestedExpand"),
+		nonEmpty: true,
+	},
+	"tableCell": {
+		content:  nestedBlocks,
+		nonEmpty: true,
+	},
+	"expand": {
+		content:  extend(nestedBlocks, "table"),
+		nonEmpty: true,
+		marks:    []string{"breakout"},
+	},
+	"nestedExpand": {
+		content:  without(nestedBlocks, "nestedExpand", "blockCard", "embedCard", "extension"),
+		nonEmpty: true,
+	},
+	"layoutSection": {
+		content:  []string{"layoutColumn"},
+		nonEmpty: true,
+		marks:    []string{"breakout"},
+		check:    checkLayoutSection,
+	},
+	"layoutColumn": {
+		content:  topBlocks,
+		nonEmpty: true,
+		required: []string{"width"},
+	},
+	"extension": {
+		required: extensionAttrs,
+		marks:    extensionMarks,
+	},
+	"inlineExtension": {
+		required: extensionAttrs,
+		marks:    extensionMarks,
+	},
+	"bodiedExtension": {
+		content:  without(topBlocks, "bodiedExtension", "multiBodiedExtension"),
+		nonEmpty: true,
+		required: extensionAttrs,
+		marks:    extensionMarks,
+	},
+	"multiBodiedExtension": {
+		content:  []string{"extensionFrame"},
+		nonEmpty: true,
+		required: extensionAttrs,
+		marks:    extensionMarks,
+	},
+	"extensionFrame": {
+		content:  without(topBlocks, "bodiedExtension", "multiBodiedExtension"),
+		nonEmpty: true,
+		marks:    extensionMarks,
+	},
+}
+
+// markSpecs is the ADF schema for each mark type
+var markSpecs = map[string]markSpec{
+	"strong":          {},
+	"em":              {},
+	"strike":          {},
+	"underline":       {},
+	"code":            {},
+	"link":            {required: []string{"href"}},
+	"textColor":       {required: []string{"color"}},
+	"backgroundColor": {required: []string{"color"}},
+	"subsup": {
+		required: []string{"type"},
+		enums:    map[string][]string{"type": {"sub", "sup"}},
+	},
+	"alignment": {
+		required: []string{"align"},
+		enums:    map[string][]string{"align": {"center", "end"}},
+	},
+	"indentation": {required: []string{"level"}},
+	"breakout": {
+		required: []string{"mode"},
+		enums:    map[string][]string{"mode": {"wide", "full-width"}},
+	},
+	"annotation": {
+		required: []string{"id", "annotationType"},
+		enums:    map[string][]string{"annotationType": {"inlineComment"}},
+	},
+	"border":       {required: []string{"size", "color"}},
+	"dataConsumer": {required: []string{"sources"}},
+	"fragment":     {required: []string{"localId"}},
+}
+
+// Validate checks a document against the ADF schema: the children each
+// node type allows, the attributes it requires and the marks it can carry.
+// It returns every problem found, or nil if the document is valid.
+func Validate(node *Node) []ValidationError {
+	if node == nil {
+		return []ValidationError{{Message: "document is empty"}}
+	}
+
+	v := &validator{}
+	if node.Type != "doc" {
+		v.fail("", "root node must be doc, got "+strconv.Quote(node.Type))
+	}
+	v.validate(node, "")
+	return v.errors
+}
+
+// validator collects the problems found in a document
+type validator struct {
+	errors []ValidationError
+}
+
+// fail records a problem at path
+func (v *validator) fail(path, message string) {
+	v.errors = append(v.errors, ValidationError{Path: path, Message: message})
+}
+
+// validate checks a node and everything inside it
+func (v *validator) validate(node *Node, path string) {
+	if node.Type == "" {
+		v.fail(path, "node has no type")
+		return
+	}
+
+	spec, ok := nodeSpecs[node.Type]
+	if !ok {
+		v.fail(path, "unknown node type "+strconv.Quote(node.Type))
+		return
+	}
+
+	v.validateAttrs(node.Type, node.Attrs, spec.required, spec.enums, path)
+
+	for i := range node.Marks {
+		mark := &node.Marks[i]
+		markPath := path + "/marks/" + strconv.Itoa(i)
+		if !contains(spec.marks, mark.Type) {
+			if _, known := markSpecs[mark.Type]; known {
+				v.fail(markPath, "mark "+strconv.Quote(mark.Type)+" is not allowed on "+node.Type)
+			} else {
+				v.fail(markPath, "unknown mark type "+strconv.Quote(mark.Type))
+			}
+			continue
+		}
+		markSpec := markSpecs[mark.Type]
+		v.validateAttrs(mark.Type+" mark", mark.Attrs, markSpec.required, markSpec.enums, markPath)
+	}
+
+	if spec.content == nil && len(node.Content) > 0 {
+		v.fail(path, node.Type+" can't have content")
+	} else if spec.nonEmpty && len(node.Content) == 0 {
+		v.fail(path, node.Type+" must have content")
+	}
+
+	if spec.check != nil {
+		spec.check(v, node, path)
+	}
+
+	for i := range node.Content {
+		child := &node.Content[i]
+		childPath := path + "/content/" + strconv.Itoa(i)
+		if _, known := nodeSpecs[child.Type]; known && spec.content != nil && !contains(spec.content, child.Type) {
+			v.fail(childPath, child.Type+" is not allowed in "+node.Type)
+		}
+		v.validate(child, childPath)
+	}
+}
+
+// validateAttrs checks that required attributes are present and that
+// attributes with a fixed set of values have one of them
+func (v *validator) validateAttrs(what string, attrs map[string]any, required []string, enums map[string][]string, path string) {
+	for _, name := range required {
+		if value, ok := attrs[name]; !ok || value == nil {
+			v.fail(path, what+" is missing required attribute "+strconv.Quote(name))
+		}
+	}
+
+	for name, allowed := range enums {
+		value, ok := attrs[name]
+		if !ok || value == nil {
+			continue
+		}
+		if s, ok := value.(string); !ok || !contains(allowed, s) {
+			v.fail(path+"/attrs/"+name, what+" attribute "+strconv.Quote(name)+" must be one of "+strings.Join(allowed, ", "))
+		}
+	}
+}
+
+// checkDoc checks the document version
+func checkDoc(v *validator, node *Node, path string) {
+	if node.Version != 1 {
+		v.fail(path+"/version", "doc version must be 1")
+	}
+}
+
+// checkText checks that text isn't empty and that code is only combined
+// with marks that can apply to a code span
+func checkText(v *validator, node *Node, path string) {
+	if node.Text == "" {
+		v.fail(path+"/text", "text must not be empty")
+	}
+
+	if !hasMark(node, "code") {
+		return
+	}
+	for i, mark := range node.Marks {
+		if mark.Type != "code" && mark.Type != "link" && mark.Type != "annotation" {
+			v.fail(path+"/marks/"+strconv.Itoa(i), "mark "+strconv.Quote(mark.Type)+" can't be combined with code")
+		}
+	}
+}
+
+// checkHeading checks that the heading level is between 1 and 6
+func checkHeading(v *validator, node *Node, path string) {
+	if _, ok := node.Attrs["level"]; !ok {
+		return
+	}
+	if level, ok := intAttr(node.Attrs, "level"); !ok || level < 1 || level > 6 {
+		v.fail(path+"/attrs/level", "heading level must be a number from 1 to 6")
+	}
+}
+
+// checkOrderedList checks that the list doesn't start below zero
+func checkOrderedList(v *validator, node *Node, path string) {
+	if _, ok := node.Attrs["order"]; !ok {
+		return
+	}
+	if order, ok := intAttr(node.Attrs, "order"); !ok || order < 0 {
+		v.fail(path+"/attrs/order", "orderedList order must be a number of at least 0")
+	}
+}
+
+// checkListItem checks that a list item starts with content that can hold
+// the list marker
+func checkListItem(v *validator, node *Node, path string) {
+	if len(node.Content) == 0 {
+		return
+	}
+	switch node.Content[0].Type {
+	case "paragraph", "codeBlock", "mediaSingle":
+	default:
+		v.fail(path+"/content/0", "listItem must start with a paragraph, codeBlock or mediaSingle")
+	}
+}
+
+// checkTaskList checks that a task list starts with a task rather than a
+// nested list
+func checkTaskList(v *validator, node *Node, path string) {
+	if len(node.Content) > 0 && node.Content[0].Type != "taskItem" {
+		v.fail(path+"/content/0", "taskList must start with a taskItem")
+	}
+}
+
+// checkCodeBlock checks that code is plain text
+func checkCodeBlock(v *validator, node *Node, path string) {
+	for i, child := range node.Content {
+		if child.Type == "text" && len(child.Marks) > 0 {
+			v.fail(path+"/content/"+strconv.Itoa(i)+"/marks", "text in a codeBlock can't have marks")
+		}
+	}
+}
+
+// checkCard checks that a smart link has either a URL or resolved data
+func checkCard(v *validator, node *Node, path string) {
+	if node.Attrs["url"] == nil && node.Attrs["data"] == nil {
+		v.fail(path, node.Type+" needs a url or data attribute")
+	}
+}
+
+// checkMediaSingle checks that the media comes first, followed by at most
+// a caption
+func checkMediaSingle(v *validator, node *Node, path string) {
+	if len(node.Content) > 0 && node.Content[0].Type != "media" {
+		v.fail(path+"/content/0", "mediaSingle must start with a media node")
+	}
+	if len(node.Content) > 2 || len(node.Content) == 2 && node.Content[1].Type != "caption" {
+		v.fail(path, "mediaSingle can only hold one media node and a caption")
+	}
+}
+
+// checkMedia checks the attributes needed to locate the media
+func checkMedia(v *validator, node *Node, path string) {
+	switch node.Attrs["type"] {
+	case "external":
+		v.validateAttrs("media", node.Attrs, []string{"url"}, nil, path)
+	case "file", "link":
+		v.validateAttrs("media", node.Attrs, []string{"id", "collection"}, nil, path)
+	}
+}
+
+// checkLayoutSection checks the number of columns in a layout
+func checkLayoutSection(v *validator, node *Node, path string) {
+	if len(node.Content) > 0 && (len(node.Content) < 2 || len(node.Content) > 3) {
+		v.fail(path, "layoutSection must have 2 or 3 columns")
+	}
+}
+
+// contains reports whether list holds s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// extend returns a copy of list with items added to the end
+func extend(list []string, items ...string) []string {
+	result := make([]string, 0, len(list)+len(items))
+	result = append(result, list...)
+	return append(result, items...)
+}
+
+// without returns a copy of list with the given items left out
+func without(list []string, items ...string) []string {
+	var result []string
+	for _, item := range list {
+		if !contains(items, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
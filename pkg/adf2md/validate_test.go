@@ -0,0 +1,93 @@
+package adf2md_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []adf2md.ValidationError
+	}{
+		{
+			name:  "Valid document",
+			input: `{"version":1,"type":"doc","content":[{"type":"heading","attrs":{"level":2},"content":[{"type":"text","text":"Title"}]},{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"link","marks":[{"type":"link","attrs":{"href":"https://example.com"}},{"type":"code"}]}]},{"type":"orderedList","attrs":{"order":3},"content":[{"type":"listItem","content":[{"type":"paragraph"}]}]}]}]},{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableHeader","content":[{"type":"paragraph","marks":[{"type":"alignment","attrs":{"align":"center"}}]}]}]}]}]}`,
+		},
+		{
+			name:  "List item outside of a list",
+			input: `{"version":1,"type":"doc","content":[{"type":"paragraph"},{"type":"listItem","content":[{"type":"paragraph"}]}]}`,
+			expected: []adf2md.ValidationError{
+				{Path: "/content/1", Message: "listItem is not allowed in doc"},
+			},
+		},
+		{
+			name:  "Heading without a level",
+			input: `{"version":1,"type":"doc","content":[{"type":"heading","content":[{"type":"text","text":"Title"}]},{"type":"heading","attrs":{"level":7}}]}`,
+			expected: []adf2md.ValidationError{
+				{Path: "/content/0", Message: `heading is missing required attribute "level"`},
+				{Path: "/content/1/attrs/level", Message: "heading level must be a number from 1 to 6"},
+			},
+		},
+		{
+			name:  "Text inside a list",
+			input: `{"version":1,"type":"doc","content":[{"type":"bulletList","content":[{"type":"text","text":"loose"}]}]}`,
+			expected: []adf2md.ValidationError{
+				{Path: "/content/0/content/0", Message: "text is not allowed in bulletList"},
+			},
+		},
+		{
+			name:  "Marks",
+			input: `{"version":1,"type":"doc","content":[{"type":"paragraph","marks":[{"type":"strong"}],"content":[{"type":"text","text":"a","marks":[{"type":"glow"},{"type":"link"},{"type":"subsup","attrs":{"type":"under"}}]},{"type":"text","text":"b","marks":[{"type":"code"},{"type":"strong"}]}]}]}`,
+			expected: []adf2md.ValidationError{
+				{Path: "/content/0/marks/0", Message: `mark "strong" is not allowed on paragraph`},
+				{Path: "/content/0/content/0/marks/0", Message: `unknown mark type "glow"`},
+				{Path: "/content/0/content/0/marks/1", Message: `link mark is missing required attribute "href"`},
+				{Path: "/content/0/content/0/marks/2/attrs/type", Message: `subsup mark attribute "type" must be one of sub, sup`},
+				{Path: "/content/0/content/1/marks/1", Message: `mark "strong" can't be combined with code`},
+			},
+		},
+		{
+			name:  "Structure",
+			input: `{"type":"doc","content":[{"type":"bulletList"},{"type":"rule","content":[{"type":"text","text":"x"}]},{"type":"codeBlock","content":[{"type":"text","text":"x","marks":[{"type":"em"}]}]},{"type":"gadget"},{"type":"mediaSingle","content":[{"type":"media","attrs":{"type":"file","id":"abc"}}]}]}`,
+			expected: []adf2md.ValidationError{
+				{Path: "/version", Message: "doc version must be 1"},
+				{Path: "/content/0", Message: "bulletList must have content"},
+				{Path: "/content/1", Message: "rule can't have content"},
+				{Path: "/content/2/content/0/marks", Message: "text in a codeBlock can't have marks"},
+				{Path: "/content/3", Message: `unknown node type "gadget"`},
+				{Path: "/content/4/content/0", Message: `media is missing required attribute "collection"`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := adf2md.ParseADF(tt.input)
+			if err != nil {
+				t.Fatalf("Failed to parse ADF: %v", err)
+			}
+
+			result := adf2md.Validate(node)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("\nExpected: %v\nGot:      %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestValidateParsedMarkdown(t *testing.T) {
+	markdown := "# Title\n\n* a\n  1. b\n\n- [ ] task\n- [x] done\n\n> quote\n\n```go\ncode\n```\n\n| A | B |\n|:-:|--:|\n| **1** | [2](https://example.com) |\n\n![image](https://example.com/i.png)\n\n---\n"
+
+	node, err := adf2md.ParseMarkdown(markdown)
+	if err != nil {
+		t.Fatalf("ParseMarkdown failed: %v", err)
+	}
+
+	if errs := adf2md.Validate(node); errs != nil {
+		t.Errorf("Parsed Markdown is not valid ADF: %v", errs)
+	}
+}
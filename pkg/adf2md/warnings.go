@@ -0,0 +1,144 @@
+package adf2md
+
+import (
+	"strconv"
+	"strings"
+)
+
+// WarningKind classifies the ways in which rendering can lose information
+type WarningKind string
+
+const (
+	// WarningUnknownNode is a node type the renderer doesn't support,
+	// written as a placeholder
+	WarningUnknownNode WarningKind = "unknown-node"
+	// WarningUnknownMark is a mark type the renderer doesn't support,
+	// dropped from the output
+	WarningUnknownMark WarningKind = "unknown-mark"
+	// WarningLossy is content that was converted but can't be shown
+	// exactly as in the document, such as text colors or merged table cells
+	WarningLossy WarningKind = "lossy"
+	// WarningMissingAttr is a node or mark lacking an attribute needed to
+	// render it, so a default was used or it was dropped
+	WarningMissingAttr WarningKind = "missing-attr"
+	// WarningUnresolvedMedia is a media node without a URL to link to
+	WarningUnresolvedMedia WarningKind = "unresolved-media"
+)
+
+// Warning describes a place where the output doesn't fully represent the
+// document
+type Warning struct {
+	Kind WarningKind
+	// The ADF type of the node or mark concerned
+	Type string
+	// Where it appears in the document, as a JSON pointer such as
+	// "/content/2/content/0/marks/1"
+	Path string
+	// What was lost
+	Message string
+}
+
+// String describes the warning and where it applies
+func (w Warning) String() string {
+	return string(w.Kind) + ": " + w.Message + " at " + location(w.Path)
+}
+
+// RenderResult holds the output of a render along with warnings about
+// anything that couldn't be converted faithfully
+type RenderResult struct {
+	Markdown string
+	// Warnings in document order
+	Warnings []Warning
+}
+
+// renderState is shared by all the writers used while rendering one
+// document
+type renderState struct {
+	// The nodes being rendered, from the root down to the current one
+	nodes []*Node
+	// Warnings found so far
+	warnings []Warning
+
+	// Markers of the enclosing lists in formats that repeat them to nest
+	// lists, such as "*#" in wiki markup
+	listMarkers string
+	// Markers of the enclosing list items in plain text, such as "2." for
+	// the items of a list nested in the second item of a numbered list
+	listItems []string
+
+	// Marker of the Markdown list item being rendered, such as "2. "
+	itemMarker string
+	// Number of columns the pipe table row being rendered is padded to
+	tableColumns int
+	// How the table cells and layout columns being rendered are written
+	cells cellLayout
+}
+
+// enter records that rendering has moved into node
+func (s *renderState) enter(node *Node) {
+	s.nodes = append(s.nodes, node)
+}
+
+// leave records that rendering of the current node has finished
+func (s *renderState) leave() {
+	s.nodes = s.nodes[:len(s.nodes)-1]
+}
+
+// current returns the node being rendered
+func (s *renderState) current() *Node {
+	if len(s.nodes) == 0 {
+		return nil
+	}
+	return s.nodes[len(s.nodes)-1]
+}
+
+// path returns the JSON pointer of the node being rendered. A node that
+// isn't part of its parent's content, such as one built by a custom
+// renderer, shows up as "-".
+func (s *renderState) path() string {
+	var path strings.Builder
+	for i := 1; i < len(s.nodes); i++ {
+		path.WriteString("/content/")
+		path.WriteString(childIndex(s.nodes[i-1], s.nodes[i]))
+	}
+	return path.String()
+}
+
+// childIndex returns the position of child in the content of parent
+func childIndex(parent, child *Node) string {
+	for i := range parent.Content {
+		if &parent.Content[i] == child {
+			return strconv.Itoa(i)
+		}
+	}
+	return "-"
+}
+
+// warn records a warning about the node being rendered
+func (s *renderState) warn(kind WarningKind, message string) {
+	node := s.current()
+	if node == nil {
+		return
+	}
+	s.warnings = append(s.warnings, Warning{Kind: kind, Type: node.Type, Path: s.path(), Message: message})
+}
+
+// warnMark records a warning about a mark on the node being rendered
+func (s *renderState) warnMark(kind WarningKind, mark *Mark, message string) {
+	node := s.current()
+	if node == nil {
+		return
+	}
+
+	// Marks may have been reordered, so find the original by type
+	index := "-"
+	for i := range node.Marks {
+		if &node.Marks[i] == mark || node.Marks[i].Type == mark.Type {
+			index = strconv.Itoa(i)
+			break
+		}
+	}
+
+	path := s.path() + "/marks/" + index
+	s.warnings = append(s.warnings, Warning{Kind: kind, Type: mark.Type, Path: path, Message: message})
+}
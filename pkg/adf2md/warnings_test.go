@@ -0,0 +1,85 @@
+package adf2md_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestRenderToResult(t *testing.T) {
+	input := `{"version":1,"type":"doc","content":[
+		{"type":"heading","content":[{"type":"text","text":"Title"}]},
+		{"type":"paragraph","content":[
+			{"type":"text","text":"red","marks":[{"type":"strong"},{"type":"textColor","attrs":{"color":"#ff5630"}}]},
+			{"type":"text","text":" under","marks":[{"type":"underline"}]},
+			{"type":"text","text":" link","marks":[{"type":"link"}]},
+			{"type":"text","text":" noted","marks":[{"type":"annotation","attrs":{"id":"a1","annotationType":"inlineComment"}}]}
+		]},
+		{"type":"mediaSingle","content":[{"type":"media","attrs":{"type":"file","collection":"c"}}]},
+		{"type":"table","content":[{"type":"tableRow","content":[
+			{"type":"tableCell","attrs":{"colspan":2},"content":[{"type":"paragraph","content":[{"type":"text","text":"wide"}]}]}
+		]}]},
+		{"type":"widget"}
+	]}`
+
+	node, err := adf2md.ParseADF(input)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	renderer := adf2md.NewRenderer().WithOptions(adf2md.RenderOptions{ListIndent: 2, TableMode: adf2md.TableModeGFM})
+	result, err := renderer.RenderToResult(node)
+	if err != nil {
+		t.Fatalf("RenderToResult failed: %v", err)
+	}
+
+	markdown, err := renderer.RenderToMarkdown(node)
+	if err != nil {
+		t.Fatalf("RenderToMarkdown failed: %v", err)
+	}
+	if result.Markdown != markdown {
+		t.Errorf("\nExpected: %q\nGot:      %q", markdown, result.Markdown)
+	}
+
+	expected := []adf2md.Warning{
+		{Kind: adf2md.WarningMissingAttr, Type: "heading", Path: "/content/0", Message: "heading has no level, using 1"},
+		{Kind: adf2md.WarningLossy, Type: "textColor", Path: "/content/1/content/0/marks/1", Message: "textColor written as inline HTML"},
+		{Kind: adf2md.WarningLossy, Type: "underline", Path: "/content/1/content/1/marks/0", Message: "underline written as inline HTML"},
+		{Kind: adf2md.WarningMissingAttr, Type: "link", Path: "/content/1/content/2/marks/0", Message: "link has no href"},
+		{Kind: adf2md.WarningUnknownMark, Type: "annotation", Path: "/content/1/content/3/marks/0", Message: `unsupported mark "annotation" dropped`},
+		{Kind: adf2md.WarningUnresolvedMedia, Type: "media", Path: "/content/2/content/0", Message: "media has no URL or id to link to"},
+		{Kind: adf2md.WarningLossy, Type: "tableCell", Path: "/content/3/content/0/content/0", Message: "merged cell split in a pipe table"},
+		{Kind: adf2md.WarningUnknownNode, Type: "widget", Path: "/content/4", Message: `unsupported node "widget" written as a placeholder`},
+	}
+	if !reflect.DeepEqual(result.Warnings, expected) {
+		t.Errorf("\nExpected: %v\nGot:      %v", expected, result.Warnings)
+	}
+}
+
+func TestRenderContextWarn(t *testing.T) {
+	node, err := adf2md.ParseADF(`{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"Hi "},{"type":"mention","attrs":{"id":"abc"}}]}]}`)
+	if err != nil {
+		t.Fatalf("Failed to parse ADF: %v", err)
+	}
+
+	renderer := adf2md.NewRenderer().Register("mention", adf2md.NodeRendererFunc(func(ctx *adf2md.RenderContext, node *adf2md.Node) error {
+		ctx.Warn(adf2md.WarningMissingAttr, "unknown user at "+ctx.Path())
+		return ctx.RenderDefault(node)
+	}))
+
+	result, err := renderer.RenderToResult(node)
+	if err != nil {
+		t.Fatalf("RenderToResult failed: %v", err)
+	}
+
+	expected := []adf2md.Warning{
+		{Kind: adf2md.WarningMissingAttr, Type: "mention", Path: "/content/0/content/1", Message: "unknown user at /content/0/content/1"},
+	}
+	if !reflect.DeepEqual(result.Warnings, expected) {
+		t.Errorf("\nExpected: %v\nGot:      %v", expected, result.Warnings)
+	}
+	if result.Markdown != "Hi @user:abc\n\n" {
+		t.Errorf("Unexpected Markdown: %q", result.Markdown)
+	}
+}
@@ -0,0 +1,526 @@
+package adf2md
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewWikiRenderer creates a renderer that writes Jira wiki markup, as used
+// by Jira Server and Data Center, instead of Markdown. It works like any
+// other Renderer: RenderToMarkdown, RenderToResult and Render return wiki
+// markup, and custom node, mark and extension renderers can be registered.
+//
+// Mentions become [~accountid:...] links, and attachments without a
+// MediaResolver are embedded by file name as !file.png!. Options that only
+// apply to Markdown syntax, such as TableMode, are ignored.
+func NewWikiRenderer() *Renderer {
+	r := NewRenderer()
+	r.syntax = wikiSyntax
+	return r
+}
+
+// wikiSyntax is the built-in rendering of Jira wiki markup
+var wikiSyntax = &syntax{
+	nodes: map[string]func(r *Renderer, w *markdownWriter, node *Node){
+		"heading":       (*Renderer).renderWikiHeading,
+		"bulletList":    (*Renderer).renderWikiList,
+		"orderedList":   (*Renderer).renderWikiList,
+		"taskList":      (*Renderer).renderWikiList,
+		"decisionList":  (*Renderer).renderWikiList,
+		"listItem":      (*Renderer).renderWikiListItem,
+		"taskItem":      (*Renderer).renderWikiListItem,
+		"decisionItem":  (*Renderer).renderWikiListItem,
+		"codeBlock":     (*Renderer).renderWikiCodeBlock,
+		"rule":          (*Renderer).renderWikiRule,
+		"blockquote":    (*Renderer).renderWikiBlockquote,
+		"hardBreak":     (*Renderer).renderWikiHardBreak,
+		"panel":         (*Renderer).renderWikiPanel,
+		"mention":       (*Renderer).renderWikiMention,
+		"date":          (*Renderer).renderWikiDate,
+		"status":        (*Renderer).renderWikiStatus,
+		"media":         (*Renderer).renderWikiMedia,
+		"mediaGroup":    (*Renderer).renderWikiMediaGroup,
+		"mediaInline":   (*Renderer).renderWikiAttachment,
+		"inlineCard":    (*Renderer).renderWikiCard,
+		"blockCard":     (*Renderer).renderWikiCard,
+		"embedCard":     (*Renderer).renderWikiCard,
+		"expand":        (*Renderer).renderWikiExpand,
+		"nestedExpand":  (*Renderer).renderWikiExpand,
+		"layoutSection": (*Renderer).renderWikiLayout,
+		"table":         (*Renderer).renderWikiTable,
+		"tableRow":      (*Renderer).renderWikiTableRow,
+		"tableHeader":   (*Renderer).renderWikiTableCell,
+		"tableCell":     (*Renderer).renderWikiTableCell,
+	},
+	marks: map[string]MarkRenderer{
+		"strong":    delimiters("*", "*"),
+		"em":        delimiters("_", "_"),
+		"strike":    delimiters("-", "-"),
+		"underline": delimiters("+", "+"),
+		"code":      delimiters("{{", "}}"),
+		"link": MarkRendererFunc(func(ctx *RenderContext, mark *Mark, text string) (string, string) {
+			href, _ := mark.Attrs["href"].(string)
+			if href == "" {
+				ctx.Warn(WarningMissingAttr, "link has no href")
+				return "", ""
+			}
+			return "[", "|" + escapeWikiURL(href) + "]"
+		}),
+		"textColor": MarkRendererFunc(func(ctx *RenderContext, mark *Mark, text string) (string, string) {
+			color, _ := mark.Attrs["color"].(string)
+			if !isCSSColor(color) {
+				return "", ""
+			}
+			return "{color:" + color + "}", "{color}"
+		}),
+		"backgroundColor": MarkRendererFunc(func(ctx *RenderContext, mark *Mark, text string) (string, string) {
+			ctx.w.state.warnMark(WarningLossy, mark, "background color dropped, as wiki markup has none")
+			return "", ""
+		}),
+		"subsup": MarkRendererFunc(func(ctx *RenderContext, mark *Mark, text string) (string, string) {
+			if kind, _ := mark.Attrs["type"].(string); kind == "sup" {
+				return "^", "^"
+			}
+			return "~", "~"
+		}),
+	},
+	escape:           escapeWiki,
+	escapeCode:       true,
+	escapeLineStarts: escapeWikiLineStarts,
+	placeholder: func(text string) string {
+		return `\[` + text + `\]`
+	},
+}
+
+// renderWikiHeading renders a heading node as "h1. Title"
+func (r *Renderer) renderWikiHeading(w *markdownWriter, node *Node) {
+	level, ok := intAttr(node.Attrs, "level")
+	if !ok {
+		w.state.warn(WarningMissingAttr, "heading has no level, using 1")
+	}
+	level = max(1, min(level, 6))
+
+	w.WriteString("h" + strconv.Itoa(level) + ". " + r.renderInline(w, node.Content))
+	w.ensureNewlines(2)
+}
+
+// wikiListMarkers maps list types to their wiki markup markers. Task and
+// decision lists are bulleted, with the state shown on each item.
+var wikiListMarkers = map[string]string{
+	"bulletList":   "*",
+	"orderedList":  "#",
+	"taskList":     "*",
+	"decisionList": "*",
+}
+
+// renderWikiList renders a list. Wiki markup nests lists by repeating the
+// markers of the enclosing lists, as in "*#" for a numbered list inside a
+// bulleted one, rather than by indenting.
+func (r *Renderer) renderWikiList(w *markdownWriter, node *Node) {
+	outer := w.state.listMarkers
+	w.state.listMarkers += wikiListMarkers[node.Type]
+
+	for i := range node.Content {
+		w.ensureNewlines(1)
+		r.renderNode(w, &node.Content[i])
+	}
+
+	w.state.listMarkers = outer
+	if outer == "" {
+		w.ensureNewlines(2)
+	} else {
+		w.ensureNewlines(1)
+	}
+}
+
+// renderWikiListItem renders a list, task or decision item. Only nested
+// lists can start new lines, so further paragraphs are joined with line
+// breaks.
+func (r *Renderer) renderWikiListItem(w *markdownWriter, node *Node) {
+	markers := w.state.listMarkers
+	if markers == "" {
+		markers = "*"
+	}
+	w.WriteString(markers + " ")
+
+	state, _ := node.Attrs["state"].(string)
+	switch node.Type {
+	case "taskItem":
+		if state == "DONE" {
+			w.WriteString("(/) ")
+		} else {
+			w.WriteString("( ) ")
+		}
+	case "decisionItem":
+		if state == "DECIDED" {
+			w.WriteString("(/) ")
+		} else {
+			w.WriteString("(?) ")
+		}
+	}
+
+	for i := range node.Content {
+		child := &node.Content[i]
+		if _, ok := wikiListMarkers[child.Type]; ok {
+			w.trimNewlines(1)
+			w.ensureNewlines(1)
+			r.renderNode(w, child)
+			continue
+		}
+		if i > 0 && contains(nestedBlocks, child.Type) {
+			w.trimNewlines(0)
+			w.WriteString(` \\ `)
+		}
+		r.renderNode(w, child)
+		w.trimNewlines(0)
+	}
+}
+
+// renderWikiCodeBlock renders a code block as a {code} macro
+func (r *Renderer) renderWikiCodeBlock(w *markdownWriter, node *Node) {
+	macro := "{code}"
+	if language, _ := node.Attrs["language"].(string); language != "" {
+		macro = "{code:" + language + "}"
+	}
+
+	var code strings.Builder
+	for _, child := range node.Content {
+		code.WriteString(child.Text)
+	}
+	w.WriteString(macro + "\n" + code.String() + "\n{code}")
+	w.ensureNewlines(2)
+}
+
+// renderWikiRule renders a horizontal rule
+func (r *Renderer) renderWikiRule(w *markdownWriter, node *Node) {
+	w.WriteString("----")
+	w.ensureNewlines(2)
+}
+
+// renderWikiBlockquote renders a blockquote as a {quote} macro
+func (r *Renderer) renderWikiBlockquote(w *markdownWriter, node *Node) {
+	r.renderWikiMacro(w, "{quote}", "{quote}", node.Content)
+}
+
+// renderWikiMacro renders block content between the opening and closing
+// tags of a macro, each on its own line
+func (r *Renderer) renderWikiMacro(w *markdownWriter, open, close string, content []Node) {
+	w.WriteString(open)
+	w.ensureNewlines(1)
+	r.renderContent(w, content)
+	w.trimNewlines(1)
+	w.ensureNewlines(1)
+	w.WriteString(close)
+	w.ensureNewlines(2)
+}
+
+// renderWikiHardBreak renders a line break within a paragraph
+func (r *Renderer) renderWikiHardBreak(w *markdownWriter, node *Node) {
+	w.WriteString(`\\ `)
+}
+
+// wikiPanelMacros maps panel types to the closest wiki markup macro
+var wikiPanelMacros = map[string]string{
+	"info":    "info",
+	"note":    "note",
+	"warning": "warning",
+	"error":   "warning",
+	"success": "tip",
+	"tip":     "tip",
+}
+
+// renderWikiPanel renders a panel as an {info}, {note}, {warning} or {tip}
+// macro. Custom panels become a {panel} in the panel's color.
+func (r *Renderer) renderWikiPanel(w *markdownWriter, node *Node) {
+	panelType, _ := node.Attrs["panelType"].(string)
+
+	if macro, ok := wikiPanelMacros[panelType]; ok {
+		r.renderWikiMacro(w, "{"+macro+"}", "{"+macro+"}", node.Content)
+		return
+	}
+
+	open := "{panel}"
+	if color, _ := node.Attrs["panelColor"].(string); isCSSColor(color) {
+		open = "{panel:bgColor=" + color + "}"
+	}
+	if icon := panelIcon(node); icon != "" {
+		w.state.warn(WarningLossy, "panel icon dropped, as wiki panels have none")
+	}
+	r.renderWikiMacro(w, open, "{panel}", node.Content)
+}
+
+// renderWikiMention renders a mention as a link to the user's account
+func (r *Renderer) renderWikiMention(w *markdownWriter, node *Node) {
+	user := r.options.mentionUser(node)
+	if user.ID != "" {
+		w.WriteString("[~accountid:" + user.ID + "]")
+		return
+	}
+
+	w.state.warn(WarningMissingAttr, "mention has no id")
+	name := user.Name
+	if !r.options.DisableEscaping {
+		name = escapeWiki(name)
+	}
+	w.WriteString("@" + name)
+}
+
+// renderWikiDate renders a date as text in the configured layout, or as an
+// ISO 8601 timestamp with DateStyleISO
+func (r *Renderer) renderWikiDate(w *markdownWriter, node *Node) {
+	t, text, ok := r.options.formatDate(w.state, node)
+	switch {
+	case !ok:
+		text = r.placeholder(strings.Trim(text, "[]"))
+	case r.options.DateStyle == DateStyleISO:
+		text = t.Format(time.RFC3339)
+	case !r.options.DisableEscaping:
+		text = escapeWiki(text)
+	}
+	w.WriteString(text)
+}
+
+// wikiStatusColors maps status colors to wiki markup colors
+var wikiStatusColors = map[string]string{
+	"purple": "#403294",
+	"blue":   "#0747a6",
+	"red":    "#bf2600",
+	"yellow": "#ff991f",
+	"green":  "#006644",
+}
+
+// renderWikiStatus renders a status lozenge as bold text in its color
+func (r *Renderer) renderWikiStatus(w *markdownWriter, node *Node) {
+	text, ok := node.Attrs["text"].(string)
+	if !ok {
+		w.state.warn(WarningMissingAttr, "status has no text")
+		text = "STATUS"
+	}
+	if !r.options.DisableEscaping {
+		text = escapeWiki(text)
+	}
+
+	color, _ := node.Attrs["color"].(string)
+	if wikiColor, ok := wikiStatusColors[color]; ok {
+		w.WriteString("{color:" + wikiColor + "}*" + text + "*{color}")
+		return
+	}
+	w.WriteString("*" + text + "*")
+}
+
+// wikiMediaSource returns what to embed or link for a media node: the URL
+// from the MediaResolver or of external media, or otherwise the file name
+// of an attachment, which Jira finds among the issue's attachments
+func (r *Renderer) wikiMediaSource(node *Node) (string, bool) {
+	if r.options.MediaResolver == nil {
+		if media := nodeMedia(node); media.Type != "external" {
+			name := media.FileName
+			if name == "" {
+				name = media.Alt
+			}
+			return name, name != ""
+		}
+	}
+	return r.options.mediaURL(node)
+}
+
+// renderWikiMedia renders a media node as an embedded image, such as
+// !diagram.png|width=640!
+func (r *Renderer) renderWikiMedia(w *markdownWriter, node *Node) {
+	source, ok := r.wikiMediaSource(node)
+	if !ok {
+		w.state.warn(WarningUnresolvedMedia, "media has no URL or file name to link to")
+		alt, _ := node.Attrs["alt"].(string)
+		if alt == "" {
+			alt = "image"
+		}
+		w.WriteString(r.placeholder("Image: " + alt))
+		return
+	}
+
+	var params []string
+	if width, ok := intAttr(node.Attrs, "width"); ok {
+		params = append(params, "width="+strconv.Itoa(width))
+	}
+	if height, ok := intAttr(node.Attrs, "height"); ok {
+		params = append(params, "height="+strconv.Itoa(height))
+	}
+	if len(params) > 0 {
+		source += "|" + strings.Join(params, ",")
+	}
+	w.WriteString("!" + source + "!")
+}
+
+// renderWikiMediaGroup renders a mediaGroup node as a list of links to the
+// files
+func (r *Renderer) renderWikiMediaGroup(w *markdownWriter, node *Node) {
+	for i := range node.Content {
+		media := &node.Content[i]
+		if media.Type != "media" {
+			continue
+		}
+		w.ensureNewlines(1)
+		w.WriteString("* ")
+		w.state.enter(media)
+		r.renderWikiAttachment(w, media)
+		w.state.leave()
+	}
+	w.ensureNewlines(2)
+}
+
+// renderWikiAttachment renders a media node as a link to the file, such as
+// [^spec.pdf] for an attachment of the issue
+func (r *Renderer) renderWikiAttachment(w *markdownWriter, node *Node) {
+	name, _ := node.Attrs["alt"].(string)
+	if name == "" {
+		name, _ = node.Attrs["id"].(string)
+	}
+
+	source, ok := r.wikiMediaSource(node)
+	switch {
+	case !ok:
+		w.state.warn(WarningUnresolvedMedia, node.Type+" has no URL or file name to link to")
+		w.WriteString(r.placeholder("Attachment: " + name))
+	case source == name:
+		w.WriteString("[^" + name + "]")
+	default:
+		w.WriteString("[" + escapeWiki(name) + "|" + escapeWikiURL(source) + "]")
+	}
+}
+
+// renderWikiCard renders a smart link as a link labelled with the linked
+// page's name if it is known
+func (r *Renderer) renderWikiCard(w *markdownWriter, node *Node) {
+	url, name, _ := cardDetails(node)
+	if url == "" {
+		w.state.warn(WarningMissingAttr, node.Type+" has no url")
+		return
+	}
+
+	if name == "" || r.options.CardStyle == CardStyleAutolink {
+		w.WriteString("[" + escapeWikiURL(url) + "]")
+	} else {
+		w.WriteString("[" + escapeWiki(name) + "|" + escapeWikiURL(url) + "]")
+	}
+
+	if node.Type != "inlineCard" {
+		w.ensureNewlines(2)
+	}
+}
+
+// renderWikiExpand renders an expand as its title in bold followed by its
+// content, as Jira wiki markup has no collapsible sections
+func (r *Renderer) renderWikiExpand(w *markdownWriter, node *Node) {
+	if title, _ := node.Attrs["title"].(string); title != "" {
+		w.WriteString("*" + escapeWiki(title) + "*")
+		w.ensureNewlines(2)
+	}
+	r.renderContent(w, node.Content)
+	w.ensureNewlines(2)
+}
+
+// renderWikiLayout renders the columns of a layout one after another
+func (r *Renderer) renderWikiLayout(w *markdownWriter, node *Node) {
+	r.renderSequentialLayout(w, layoutColumns(node))
+}
+
+// renderWikiTable renders a table with ||header|| and |cell| rows. Merged
+// cells can't be expressed and are written as single cells.
+func (r *Renderer) renderWikiTable(w *markdownWriter, node *Node) {
+	for _, row := range tableRows(node) {
+		w.ensureNewlines(1)
+		r.renderNode(w, row)
+	}
+	w.ensureNewlines(2)
+}
+
+// renderWikiTableRow renders a table row on a single line
+func (r *Renderer) renderWikiTableRow(w *markdownWriter, node *Node) {
+	last := "|"
+	for i := range node.Content {
+		r.renderNode(w, &node.Content[i])
+		last = wikiCellDelimiter(&node.Content[i])
+	}
+	w.WriteString(last)
+}
+
+// renderWikiTableCell renders a table cell, opened with || for headers and
+// | for other cells. Blank lines would end the table, so blocks in the cell
+// are kept on consecutive lines.
+func (r *Renderer) renderWikiTableCell(w *markdownWriter, node *Node) {
+	if isMergedCell(node) {
+		w.state.warn(WarningLossy, "merged cell written as a single cell")
+	}
+
+	content := strings.TrimSpace(r.renderBlocks(w, node.Content))
+	for strings.Contains(content, "\n\n") {
+		content = strings.ReplaceAll(content, "\n\n", "\n")
+	}
+	if content == "" {
+		content = " "
+	}
+	w.WriteString(wikiCellDelimiter(node) + content)
+}
+
+// wikiCellDelimiter returns the delimiter that opens a table cell
+func wikiCellDelimiter(cell *Node) string {
+	if cell.Type == "tableHeader" {
+		return "||"
+	}
+	return "|"
+}
+
+// escapeWiki escapes characters that wiki markup would read as formatting,
+// links, macros or table cells. Formatting characters only take effect at
+// the edge of a word, so those inside words, like the hyphen in "built-in",
+// are left alone.
+func escapeWiki(text string) string {
+	if !strings.ContainsAny(text, `{}[]|!*_+-^~\`) {
+		return text
+	}
+
+	runes := []rune(text)
+	var result strings.Builder
+	for i, c := range runes {
+		switch c {
+		case '\\':
+			// A backslash can't escape another, since \\ is a line break
+			result.WriteString("&#92;")
+			continue
+		case '{', '}', '[', ']', '|':
+			result.WriteByte('\\')
+		case '!':
+			// Only an exclamation mark followed by text can start an image
+			if i+1 < len(runes) && runes[i+1] != ' ' && runes[i+1] != '!' {
+				result.WriteByte('\\')
+			}
+		case '*', '_', '+', '-', '^', '~':
+			inWord := i > 0 && isWordRune(runes[i-1]) && i+1 < len(runes) && isWordRune(runes[i+1])
+			if !inWord {
+				result.WriteByte('\\')
+			}
+		}
+		result.WriteRune(c)
+	}
+	return result.String()
+}
+
+// wikiURLEscaper percent-encodes the characters that would end a link in
+// wiki markup
+var wikiURLEscaper = strings.NewReplacer("|", "%7C", "[", "%5B", "]", "%5D")
+
+// escapeWikiURL makes a URL safe to use as the destination of a wiki link
+func escapeWikiURL(url string) string {
+	return wikiURLEscaper.Replace(url)
+}
+
+// escapeWikiLineStarts escapes a # at the start of a line, which would
+// start a numbered list. Other list markers are already escaped by
+// escapeWiki.
+func escapeWikiLineStarts(text string, atLineStart bool) string {
+	if atLineStart && strings.HasPrefix(text, "#") {
+		text = `\` + text
+	}
+	return strings.ReplaceAll(text, "\n#", "\n\\#")
+}
@@ -0,0 +1,158 @@
+package adf2md_test
+
+import (
+	"testing"
+
+	"github.com/carylee/adf2md/pkg/adf2md"
+)
+
+func TestRenderWiki(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "Heading and marks",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"heading","attrs":{"level":2},"content":[{"type":"text","text":"Release notes"}]},
+				{"type":"paragraph","content":[
+					{"type":"text","text":"bold","marks":[{"type":"strong"}]},
+					{"type":"text","text":" "},
+					{"type":"text","text":"code","marks":[{"type":"code"}]},
+					{"type":"text","text":" "},
+					{"type":"text","text":"docs","marks":[{"type":"link","attrs":{"href":"https://example.com"}}]},
+					{"type":"text","text":" "},
+					{"type":"text","text":"red","marks":[{"type":"textColor","attrs":{"color":"#ff0000"}}]}
+				]}
+			]}`,
+			expected: "h2. Release notes\n\n*bold* {{code}} [docs|https://example.com] {color:#ff0000}red{color}\n\n",
+		},
+		{
+			name: "Escaping",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"paragraph","content":[{"type":"text","text":"# 1 *not bold* [x] a built-in {macro}!"}]}
+			]}`,
+			expected: "\\# 1 \\*not bold\\* \\[x\\] a built-in \\{macro\\}!\n\n",
+		},
+		{
+			name: "Backslashes",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"paragraph","content":[{"type":"text","text":"C:\\\\server\\share \\*"}]}
+			]}`,
+			expected: "C:&#92;&#92;server&#92;share &#92;\\*\n\n",
+		},
+		{
+			name: "Link with markup in its URL",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"paragraph","content":[
+					{"type":"text","text":"search","marks":[{"type":"link","attrs":{"href":"https://example.com/?q=a|b&f[]=c"}}]}
+				]}
+			]}`,
+			expected: "[search|https://example.com/?q=a%7Cb&f%5B%5D=c]\n\n",
+		},
+		{
+			name: "Markup in code",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"paragraph","content":[{"type":"text","text":"a}}b *x*","marks":[{"type":"code"}]}]}
+			]}`,
+			expected: "{{a\\}\\}b \\*x\\*}}\n\n",
+		},
+		{
+			name: "Nested lists",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"bulletList","content":[
+					{"type":"listItem","content":[
+						{"type":"paragraph","content":[{"type":"text","text":"One"}]},
+						{"type":"orderedList","content":[
+							{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"First"}]}]},
+							{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"Second"}]}]}
+						]}
+					]},
+					{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"Two"}]}]}
+				]}
+			]}`,
+			expected: "* One\n*# First\n*# Second\n* Two\n\n",
+		},
+		{
+			name: "Task list",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"taskList","attrs":{"localId":"l"},"content":[
+					{"type":"taskItem","attrs":{"localId":"a","state":"DONE"},"content":[{"type":"text","text":"Ship it"}]},
+					{"type":"taskItem","attrs":{"localId":"b","state":"TODO"},"content":[{"type":"text","text":"Announce it"}]}
+				]}
+			]}`,
+			expected: "* (/) Ship it\n* ( ) Announce it\n\n",
+		},
+		{
+			name: "Code block",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"codeBlock","attrs":{"language":"go"},"content":[{"type":"text","text":"fmt.Println(\"*hi*\")"}]}
+			]}`,
+			expected: "{code:go}\nfmt.Println(\"*hi*\")\n{code}\n\n",
+		},
+		{
+			name: "Panels",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"panel","attrs":{"panelType":"error"},"content":[{"type":"paragraph","content":[{"type":"text","text":"Down"}]}]},
+				{"type":"panel","attrs":{"panelType":"custom","panelColor":"#eae6ff"},"content":[{"type":"paragraph","content":[{"type":"text","text":"Custom"}]}]}
+			]}`,
+			expected: "{warning}\nDown\n{warning}\n\n{panel:bgColor=#eae6ff}\nCustom\n{panel}\n\n",
+		},
+		{
+			name: "Mention and status",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"paragraph","content":[
+					{"type":"mention","attrs":{"id":"5b10ac8d82e05b22cc7d4ef5","text":"@Jane"}},
+					{"type":"text","text":" "},
+					{"type":"status","attrs":{"text":"DONE","color":"green"}}
+				]}
+			]}`,
+			expected: "[~accountid:5b10ac8d82e05b22cc7d4ef5] {color:#006644}*DONE*{color}\n\n",
+		},
+		{
+			name: "Table",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"table","content":[
+					{"type":"tableRow","content":[
+						{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Name"}]}]},
+						{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Notes"}]}]}
+					]},
+					{"type":"tableRow","content":[
+						{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"a|b"}]}]},
+						{"type":"tableCell","content":[
+							{"type":"paragraph","content":[{"type":"text","text":"One"}]},
+							{"type":"paragraph","content":[{"type":"text","text":"Two"}]}
+						]}
+					]}
+				]}
+			]}`,
+			expected: "||Name||Notes||\n|a\\|b|One\nTwo|\n\n",
+		},
+		{
+			name: "Attachment",
+			input: `{"version":1,"type":"doc","content":[
+				{"type":"mediaSingle","content":[{"type":"media","attrs":{"id":"abc","type":"file","collection":"jira","alt":"diagram.png","width":640}}]}
+			]}`,
+			expected: "!diagram.png|width=640!\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := adf2md.ParseADF(tt.input)
+			if err != nil {
+				t.Fatalf("Failed to parse ADF: %v", err)
+			}
+
+			result, err := adf2md.NewWikiRenderer().RenderToMarkdown(node)
+			if err != nil {
+				t.Fatalf("RenderToMarkdown failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("\nExpected: %q\nGot:      %q", tt.expected, result)
+			}
+		})
+	}
+}
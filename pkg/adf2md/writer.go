@@ -0,0 +1,205 @@
+package adf2md
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// linePrefix is written at the start of every line inside a block, such as
+// the indentation of a list item or the "> " of a blockquote
+type linePrefix struct {
+	// Written at the start of the block's first line, e.g. a list marker
+	first string
+	// Written at the start of every following line
+	rest string
+	// Whether the first line has been started
+	used bool
+}
+
+// markdownWriter writes rendered Markdown to an io.Writer. It keeps a stack
+// of line prefixes so nested blocks are indented as they are written, and
+// holds back newlines between blocks until more content arrives so that
+// containers can tighten the spacing of their children.
+type markdownWriter struct {
+	out      io.Writer
+	err      error
+	prefixes []linePrefix
+
+	// State of the document being rendered, shared with sub-writers
+	state *renderState
+	// Context handed to custom renderers, created on first use
+	context *RenderContext
+
+	// Whether the last thing written was a newline (or nothing at all)
+	lineStart bool
+	// Newlines requested by the last block but not written yet
+	pending int
+	// Number of bytes of content written so far
+	written int
+}
+
+// newMarkdownWriter creates a markdownWriter that writes to out
+func newMarkdownWriter(out io.Writer) *markdownWriter {
+	return &markdownWriter{out: out, lineStart: true, state: &renderState{}}
+}
+
+// subWriter creates a markdownWriter that writes part of the same document
+// to out, such as content that has to be rendered before it can be placed
+func (w *markdownWriter) subWriter(out io.Writer) *markdownWriter {
+	return &markdownWriter{out: out, lineStart: true, state: w.state}
+}
+
+// WriteString writes inline content, starting each line with the current
+// prefixes. Empty lines get the prefixes with trailing spaces trimmed.
+func (w *markdownWriter) WriteString(s string) {
+	if s == "" {
+		return
+	}
+	w.flushNewlines()
+
+	for s != "" {
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			w.writeLine(s)
+			return
+		}
+		w.writeLine(s[:i])
+		w.newline()
+		s = s[i+1:]
+	}
+}
+
+// startLine writes the prefixes for a line even if no content follows,
+// such as the marker of an empty list item
+func (w *markdownWriter) startLine() {
+	w.flushNewlines()
+	if w.lineStart {
+		w.writePrefixes(false)
+		w.lineStart = false
+	}
+}
+
+// ensureNewlines asks for at least n newlines before the next content. At
+// the very start of the output this does nothing.
+func (w *markdownWriter) ensureNewlines(n int) {
+	if w.written > 0 && n > w.pending {
+		w.pending = n
+	}
+}
+
+// trimNewlines drops requested newlines beyond n
+func (w *markdownWriter) trimNewlines(n int) {
+	if w.pending > n {
+		w.pending = n
+	}
+}
+
+// atLineStart reports whether the next content will start a new line
+func (w *markdownWriter) atLineStart() bool {
+	return w.lineStart || w.pending > 0
+}
+
+// pushPrefix starts a block whose first line begins with first and whose
+// other lines begin with rest
+func (w *markdownWriter) pushPrefix(first, rest string) {
+	w.prefixes = append(w.prefixes, linePrefix{first: first, rest: rest})
+}
+
+// popPrefix ends the innermost prefixed block
+func (w *markdownWriter) popPrefix() {
+	w.prefixes = w.prefixes[:len(w.prefixes)-1]
+}
+
+// prefixWidth returns the number of characters the prefixes take up at
+// the start of a line
+func (w *markdownWriter) prefixWidth() int {
+	width := 0
+	for _, p := range w.prefixes {
+		width += utf8.RuneCountInString(p.rest)
+	}
+	return width
+}
+
+// finish writes any newlines still pending and returns the first write error
+func (w *markdownWriter) finish() error {
+	w.flushNewlines()
+	return w.err
+}
+
+// flushNewlines writes the pending newlines. If content already ended with
+// a newline, that counts as the first one.
+func (w *markdownWriter) flushNewlines() {
+	if w.lineStart && w.pending > 0 {
+		w.pending--
+	}
+	for ; w.pending > 0; w.pending-- {
+		w.newline()
+	}
+}
+
+// writeLine writes text that contains no newline
+func (w *markdownWriter) writeLine(s string) {
+	if s == "" {
+		return
+	}
+	if w.lineStart {
+		w.writePrefixes(false)
+		w.lineStart = false
+	}
+	w.write(s)
+}
+
+// newline ends the current line. A line with no content still gets its
+// prefixes, without trailing spaces, so blockquotes stay unbroken.
+func (w *markdownWriter) newline() {
+	if w.lineStart {
+		w.writePrefixes(true)
+	}
+	w.write("\n")
+	w.lineStart = true
+}
+
+// writePrefixes writes the prefixes for a new line
+func (w *markdownWriter) writePrefixes(blank bool) {
+	// A blank line only belongs to blocks that have already started, so
+	// the spacing before a block's first line stays outside of it
+	if blank {
+		var prefix strings.Builder
+		for _, p := range w.prefixes {
+			if !p.used {
+				break
+			}
+			prefix.WriteString(p.rest)
+		}
+		w.write(strings.TrimRight(prefix.String(), " "))
+		return
+	}
+
+	for i := range w.prefixes {
+		p := &w.prefixes[i]
+		if p.used {
+			w.write(p.rest)
+		} else {
+			w.write(p.first)
+			p.used = true
+		}
+	}
+}
+
+// fail records an error that stops all further output. Only the first
+// error is kept.
+func (w *markdownWriter) fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// write sends text to the underlying writer, remembering the first error
+func (w *markdownWriter) write(s string) {
+	if w.err != nil || s == "" {
+		return
+	}
+	_, w.err = io.WriteString(w.out, s)
+	w.written += len(s)
+}